@@ -0,0 +1,281 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// loadBaseline reads the -baseline file. No path means no baseline.
+func loadBaseline(filePath string) (*baseline, error) {
+	if filePath == "" {
+		return nil, nil
+	}
+	b := &baseline{path: filePath}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	var problems []string
+	for i, e := range b.Entries {
+		if e.Resource == "" {
+			problems = append(problems, fmt.Sprintf("entries[%d]: resource is required", i))
+		}
+		if e.Category != "" && !slices.Contains(resultCategories, e.Category) {
+			problems = append(problems, fmt.Sprintf("entries[%d]: unknown category %q", i, e.Category))
+		}
+		if e.Expires != "" {
+			if _, err := time.Parse(time.DateOnly, e.Expires); err != nil {
+				problems = append(problems, fmt.Sprintf("entries[%d]: expires %q is not a YYYY-MM-DD date", i, e.Expires))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%s: %s", filePath, strings.Join(problems, "; "))
+	}
+	return b, nil
+}
+
+// expired reports whether the entry no longer applies. An entry applies through its expiry day.
+func (e baselineEntry) expired(now time.Time) bool {
+	if e.Expires == "" {
+		return false
+	}
+	until, err := time.ParseInLocation(time.DateOnly, e.Expires, now.Location())
+	return err != nil || !now.Before(until.AddDate(0, 0, 1))
+}
+
+// matches reports whether the entry describes a result. Empty kind and category match any, and
+// the resource may be a glob such as aws_iam_role.*.
+func (e baselineEntry) matches(category string, item JSONResultItem) bool {
+	if e.Category != "" && e.Category != category {
+		return false
+	}
+	if e.Kind != "" && e.Kind != item.Kind {
+		return false
+	}
+	return e.matchesResource(item.Resource)
+}
+
+// matchesResource compares the resource of the entry with an address. Only * and ? are wildcards:
+// the brackets of indexed addresses such as aws_subnet.s[0] or module.x["a"].aws_iam_role.r are
+// matched literally, path.Match would take them for character classes.
+func (e baselineEntry) matchesResource(address string) bool {
+	if e.Resource == address {
+		return true
+	}
+	if !strings.ContainsAny(e.Resource, "*?") {
+		return false
+	}
+	pattern := strings.NewReplacer(`\`, `\\`, `[`, `\[`, `]`, `\]`).Replace(e.Resource)
+	ok, _ := path.Match(pattern, address)
+	return ok
+}
+
+// suppression returns the unexpired entry that accepts a result, if any.
+func (b *baseline) suppression(category string, item JSONResultItem) (baselineEntry, bool) {
+	if b == nil {
+		return baselineEntry{}, false
+	}
+	now := time.Now()
+	for _, e := range b.Entries {
+		if e.matches(category, item) && !e.expired(now) {
+			return e, true
+		}
+	}
+	return baselineEntry{}, false
+}
+
+// expiredEntries returns the entries whose expiry date has passed; their findings show up again.
+func (b *baseline) expiredEntries() []baselineEntry {
+	if b == nil {
+		return nil
+	}
+	var expired []baselineEntry
+	now := time.Now()
+	for _, e := range b.Entries {
+		if e.expired(now) {
+			expired = append(expired, e)
+		}
+	}
+	return expired
+}
+
+// visibleResults returns the results of a category that the baseline does not suppress, and how
+// many it does. With all set, the suppressed results are returned too.
+func visibleResults(report *JSONOutput, category string, all bool) ([]JSONResultItem, int) {
+	results := report.Results.GetCategory(category)
+	if knownFindings == nil {
+		return results, 0
+	}
+	visible := make([]JSONResultItem, 0, len(results))
+	suppressed := 0
+	for _, item := range results {
+		if _, ok := knownFindings.suppression(category, item); ok {
+			suppressed++
+			if !all {
+				continue
+			}
+		}
+		visible = append(visible, item)
+	}
+	return visible, suppressed
+}
+
+// results returns the results of a category shown in the TUI, with or without the suppressed ones.
+func (m model) results(category string) []JSONResultItem {
+	results, _ := visibleResults(m.report, category, m.showSuppressed)
+	return results
+}
+
+// toggleSuppressed shows or hides the results the baseline accepts.
+func (m *model) toggleSuppressed() tea.Cmd {
+	if knownFindings == nil {
+		m.setNotification(fmt.Sprintf("No baseline loaded, start with -%s", argBaseline), true)
+		return m.clearNotificationAfter(2 * time.Second)
+	}
+	m.showSuppressed = !m.showSuppressed
+	m.refreshLists()
+	return nil
+}
+
+// renderSuppression renders why a result is suppressed for the detail views.
+func renderSuppression(category string, item JSONResultItem) string {
+	e, ok := knownFindings.suppression(category, item)
+	if !ok {
+		return ""
+	}
+	line := fmt.Sprintf("%s %s", titleStyle.Render("Baseline:"), orDash(e.Reason))
+	if e.Expires != "" {
+		line += helpStyle.Render(fmt.Sprintf(" (until %s)", e.Expires))
+	}
+	return line + "\n"
+}
+
+// generateBaseline accepts every finding of the reports that the existing baseline does not cover
+// yet. Existing entries are kept as they are, including their reasons and expired dates, so an
+// expired acceptance is never silently renewed.
+func generateBaseline(reports []*JSONOutput, existing *baseline) baseline {
+	var b baseline
+	if existing != nil {
+		b.Entries = append(b.Entries, existing.Entries...)
+	}
+	covered := func(category string, item JSONResultItem) bool {
+		for _, e := range b.Entries {
+			if e.matches(category, item) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, report := range reports {
+		for _, cat := range resultCategories {
+			if cat == "INFO" || cat == "OK" {
+				continue
+			}
+			for _, item := range report.Results.GetCategory(cat) {
+				if !covered(cat, item) {
+					b.Entries = append(b.Entries, baselineEntry{Resource: item.Resource, Kind: item.Kind, Category: cat})
+				}
+			}
+		}
+	}
+	sort.SliceStable(b.Entries, func(i, j int) bool {
+		if b.Entries[i].Category != b.Entries[j].Category {
+			return b.Entries[i].Category < b.Entries[j].Category
+		}
+		return b.Entries[i].Resource < b.Entries[j].Resource
+	})
+	return b
+}
+
+// printBaseline writes a baseline for the -input report(s) to stdout, for -generate-baseline.
+func printBaseline() error {
+	paths, err := resolveReportInputs(*figs.String(argInputFile))
+	if err != nil {
+		return err
+	}
+	var reports []*JSONOutput
+	for _, path := range paths {
+		report, err := loadReportData(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		reports = append(reports, report)
+	}
+	b := generateBaseline(reports, knownFindings)
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// checkFailOn lists the findings of the -input report(s) in the -fail-on categories that the
+// baseline does not accept, and fails when there are any.
+func checkFailOn(categories []string) error {
+	paths, err := resolveReportInputs(*figs.String(argInputFile))
+	if err != nil {
+		return err
+	}
+	found := 0
+	for _, path := range paths {
+		report, err := loadReportData(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		for _, cat := range categories {
+			results, suppressed := visibleResults(report, cat, false)
+			for _, item := range results {
+				line := fmt.Sprintf("%s %-16s %s (%s)", path, cat, item.Resource, item.Kind)
+				if action, conflict := plannedResult(cat, item); conflict != "" {
+					line += fmt.Sprintf(" plan: %s, %s", action, conflict)
+				} else if action != "" {
+					line += " plan: " + action
+				}
+				fmt.Println(line)
+			}
+			if suppressed > 0 {
+				fmt.Printf("%s %-16s %d accepted by the baseline\n", path, cat, suppressed)
+			}
+			found += len(results)
+		}
+	}
+	for _, e := range knownFindings.expiredEntries() {
+		_, _ = fmt.Fprintf(os.Stderr, "baseline entry for %s expired on %s\n", e.Resource, e.Expires)
+	}
+	if found > 0 {
+		return fmt.Errorf("%d findings in -%s categories %s", found, argFailOn, strings.Join(categories, ", "))
+	}
+	return nil
+}
+
+// assureCategories is the figtree validator for -fail-on.
+func assureCategories(value interface{}) error {
+	var categories []string
+	switch v := value.(type) {
+	case []string:
+		categories = v
+	case *[]string:
+		if v != nil {
+			categories = *v
+		}
+	}
+	for _, cat := range categories {
+		if !slices.Contains(resultCategories, cat) {
+			return fmt.Errorf("-%s: unknown category %q, expected one of %s", argFailOn, cat, strings.Join(resultCategories, ", "))
+		}
+	}
+	return nil
+}
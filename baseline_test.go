@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBaselineEntryMatchesResource(t *testing.T) {
+	tests := []struct {
+		entry   string
+		address string
+		want    bool
+	}{
+		{"aws_subnet.s[0]", "aws_subnet.s[0]", true},
+		{"aws_subnet.s[0]", "aws_subnet.s0", false},
+		{"aws_subnet.s[0]", "aws_subnet.s[1]", false},
+		{`module.x["us-east-1"].aws_iam_role.r`, `module.x["us-east-1"].aws_iam_role.r`, true},
+		{`module.x["us-east-1"].aws_iam_role.r`, `module.x["u"].aws_iam_role.r`, false},
+		{`module.x["a"].*`, `module.x["a"].aws_iam_role.r`, true},
+		{`module.x["a"].*`, `module.x["b"].aws_iam_role.r`, false},
+		{"aws_subnet.s[?]", "aws_subnet.s[3]", true},
+		{"aws_subnet.s[?]", "aws_subnet.s3", false},
+		{"aws_iam_role.*", "aws_iam_role.admin", true},
+		{"aws_iam_role.*", "aws_iam_policy.admin", false},
+		{"aws_iam_role.r", "aws_iam_role.r2", false},
+	}
+	for _, tt := range tests {
+		e := baselineEntry{Resource: tt.entry}
+		if got := e.matchesResource(tt.address); got != tt.want {
+			t.Errorf("entry %q matches %q = %v, want %v", tt.entry, tt.address, got, tt.want)
+		}
+	}
+}
+
+func TestBaselineSuppressesIndexedAddress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	data := `{"entries": [
+		{"resource": "aws_subnet.s[0]", "category": "ERROR", "reason": "known"},
+		{"resource": "module.x[\"a\"].*", "kind": "aws_iam_role"}
+	]}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	b, err := loadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e, ok := b.suppression("ERROR", JSONResultItem{Resource: "aws_subnet.s[0]", Kind: "aws_subnet"}); !ok || e.Reason != "known" {
+		t.Errorf("aws_subnet.s[0] is not suppressed")
+	}
+	for _, item := range []JSONResultItem{
+		{Resource: "aws_subnet.s0", Kind: "aws_subnet"},
+		{Resource: "aws_subnet.s[1]", Kind: "aws_subnet"},
+	} {
+		if _, ok := b.suppression("ERROR", item); ok {
+			t.Errorf("%s is suppressed", item.Resource)
+		}
+	}
+	if _, ok := b.suppression("WARNING", JSONResultItem{Resource: `module.x["a"].aws_iam_role.r`, Kind: "aws_iam_role"}); !ok {
+		t.Errorf(`module.x["a"].aws_iam_role.r is not suppressed`)
+	}
+	if _, ok := b.suppression("WARNING", JSONResultItem{Resource: `module.x["a"].aws_iam_policy.p`, Kind: "aws_iam_policy"}); ok {
+		t.Errorf(`module.x["a"].aws_iam_policy.p is suppressed`)
+	}
+}
+
+func TestLinkedResultShowsItsSuppressedCategory(t *testing.T) {
+	m := newNavModel(t, "dev")
+	accepted := JSONResultItem{Resource: "aws_s3_bucket.b1", Kind: "aws_s3_bucket", Message: "accepted"}
+	m.report.Results.WarningResults = append(m.report.Results.WarningResults, accepted)
+	findings := knownFindings
+	t.Cleanup(func() { knownFindings = findings })
+	knownFindings = &baseline{Entries: []baselineEntry{{Resource: accepted.Resource}}}
+
+	m = press(t, m, "down", "enter", "R")
+	category, ok := m.resultsCategoryList.SelectedItem().(resultCategoryItem)
+	if !ok || category.name != "WARNING" || category.count != 2 || category.suppressed != 0 {
+		t.Errorf("selected category = %+v, want WARNING with the accepted result counted", category)
+	}
+	if result, ok := m.resultsResourceList.SelectedItem().(resultItem); !ok || result.Resource != accepted.Resource {
+		t.Errorf("selected result = %+v, want %s", m.resultsResourceList.SelectedItem(), accepted.Resource)
+	}
+}
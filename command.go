@@ -50,11 +50,11 @@ func editCommand(commandStr string) tea.Cmd {
 	}
 }
 
-// execCommand prepares and runs an exec.Cmd, returning a message with the output.
-func execCommand(commandStr string) tea.Cmd {
+// execCommand prepares and runs an exec.Cmd in the context of the profile, returning a message
+// with the output. The zero profile runs with the environment, a profile only in its directory.
+func execCommand(commandStr string, p profile) tea.Cmd {
 	return func() tea.Msg {
-		tfDir := os.Getenv(envTfDir)
-		tfState := os.Getenv(envTfState)
+		tfDir, tfState := p.dirAndState()
 
 		// Modify command if necessary
 		if !strings.Contains(commandStr, "-state=") && tfState != "" {
@@ -62,8 +62,15 @@ func execCommand(commandStr string) tea.Cmd {
 		}
 
 		cmd := exec.Command("sh", "-c", commandStr)
-		if isValidPath(tfDir) {
+		switch {
+		case isValidPath(tfDir):
 			cmd.Dir = tfDir
+		case p.Name != "":
+			// the credentials of a profile are for its directory, never run them in another one
+			return commandOutputMsg{err: fmt.Errorf("profile %s: terraform directory %q does not exist", p.Name, tfDir)}
+		}
+		if p.Name != "" {
+			cmd.Env = p.environ()
 		}
 
 		var out, errOut strings.Builder
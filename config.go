@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 )
 
 func setup() {
@@ -40,11 +41,10 @@ func application() error {
 		setup()
 	}
 	// -input
-	figs = figs.NewString(argInputFile, filepath.Join(".", "report.dev.json"), "Path to input file (report.<env>.json)")
+	figs = figs.NewString(argInputFile, filepath.Join(".", "report.dev.json"), "Path to input file (report.<env>.json), a directory of reports or a glob such as 'report.*.json'")
 	figs = figs.WithAlias(argInputFile, argAliasInputFile)
 	figs = figs.WithValidator(argInputFile, figtree.AssureStringNoPrefix("~"))
-	figs = figs.WithValidator(argInputFile, figtree.AssureStringHasSuffix(".json"))
-	figs = figs.WithValidator(argInputFile, figtree.AssureStringLengthGreaterThan(7))
+	figs = figs.WithValidator(argInputFile, assureReportInput)
 
 	// -contains
 	figs = figs.NewString(argCommandContains, "", "Substring search of executed command to select when using -non-interactive")
@@ -75,6 +75,76 @@ func application() error {
 	githubEnabled, _ := strconv.ParseBool(os.Getenv(envGitHub))
 	figs = figs.NewBool(argGitHub, githubEnabled, "Indicate if running in a GitHub Actions environment")
 
+	// -validate
+	figs = figs.NewBool(argValidate, false, "validate the -input report against the schema and exit non-zero on structural problems")
+
+	// -watch
+	figs = figs.NewBool(argWatch, false, "reload the -input report(s) in the TUI when they change on disk")
+	figs = figs.NewDuration(argWatchInterval, 2*time.Second, "how often -watch checks the report(s) for changes")
+
+	// -triage
+	figs = figs.NewBool(argTriage, false, "print the triage summary of the -input report(s) from their .annotations.json files and exit")
+
+	// -baseline
+	figs = figs.NewString(argBaseline, "", "baseline file of accepted findings, left out of counts, -fail-on and exports")
+	figs = figs.NewBool(argGenerateBaseline, false, "print a baseline that accepts every finding of the -input report(s), keeping the entries of -baseline, and exit")
+	figs = figs.NewList(argFailOn, []string{}, "exit non-zero when the -input report(s) have results in these categories that -baseline does not accept, e.g. DANGEROUS,ERROR")
+	figs = figs.WithValidator(argFailOn, assureCategories)
+
+	// -history
+	figs = figs.NewString(argHistory, "", "history store of ingested reports (default history.json in the -save directory)")
+	figs = figs.NewString(argIngest, "", "add the reports in a file, directory, glob or s3://bucket/prefix to the -history store and exit")
+	figs = figs.NewBool(argTrend, false, "print the category counts, flapping resources and failing commands of the -history store and exit")
+
+	// -redact
+	figs = figs.NewList(argRedact, []string{}, "extra regular expressions to redact from logs, on top of the built-in AWS key, token and private key detectors")
+	figs = figs.WithValidator(argRedact, assureRedactPatterns)
+	figs = figs.NewBool(argNoRedact, false, "show secrets in logs unredacted, for local debugging only")
+
+	// -profile
+	figs = figs.NewString(argProfiles, filepath.Join(filepath.Dir(configFile()), "profiles.yaml"), "YAML file of named profiles: tf_dir, tf_state, aws_profile, aws_region, report_region and env")
+	figs = figs.NewString(argProfile, "", "name of the -profiles entry that commands run with (press P in the TUI to switch)")
+
+	// -refactor
+	figs = figs.NewBool(argRefactor, false, "print moved and removed blocks for the successful terraform state mv and rm commands of the -input report(s) and exit")
+
+	// -replay
+	figs = figs.NewBool(argReplay, false, "print a bash script that replays the execution logs of the -input report selected by -contains and exit")
+
+	// -serve
+	figs = figs.NewString(argServe, "", "serve the -input report(s) as a JSON API and a read-only web UI on this address, e.g. :8080")
+	figs = figs.NewString(argServeToken, os.Getenv(envServeToken), "bearer token that enables running suggested commands over -serve, off when empty")
+
+	// -metrics
+	figs = figs.NewString(argMetrics, "", "serve Prometheus gauges of the -input report(s) on this address at /metrics, e.g. :9108")
+	figs = figs.NewString(argMetricsFile, "", "write the Prometheus gauges of the -input report(s) to this .prom file for the node_exporter textfile collector and exit")
+
+	// -notify
+	figs = figs.NewString(argNotify, "", "post a summary of the -input report(s) to a webhook and exit: slack, teams or json")
+	figs = figs.WithValidator(argNotify, assureNotifyFormat)
+	figs = figs.NewString(argNotifyURL, os.Getenv(envNotifyURL), "webhook URL that -notify posts to")
+	figs = figs.NewString(argNotifyTemplate, "", "text/template file that renders the -notify payload instead of the built-in one of the format")
+	figs = figs.NewString(argNotifyBaseline, "", "previous report(s) to list only the DANGEROUS results that are new since, matched by environment")
+	figs = figs.NewBool(argNotifyDryRun, false, "print the -notify payload instead of posting it")
+
+	// -tickets
+	figs = figs.NewString(argTickets, "", "print a ticket for every ERROR and DANGEROUS result of the -input report(s) and exit: jira (CSV import) or github (issues JSON)")
+	figs = figs.WithValidator(argTickets, assureTicketFormat)
+	figs = figs.NewString(argTicketsPush, "", "post the -tickets that are not in the -tickets-ledger yet to this endpoint, e.g. https://api.github.com/repos/OWNER/REPO/issues")
+	figs = figs.NewString(argTicketsToken, os.Getenv(envTicketsToken), "bearer token for -tickets-push")
+	figs = figs.NewString(argTicketsProject, "", "Jira project key of the -tickets")
+	figs = figs.NewString(argTicketsLedger, "", "file of the tickets pushed so far (default tickets.json in the -save directory)")
+
+	// -plan
+	figs = figs.NewString(argPlan, "", "terraform show -json output of a saved plan, to show the planned action of each result and flag conflicts")
+
+	// -terraform
+	figs = figs.NewString(argTerraform, "terraform", "terraform binary the import preview plans with")
+
+	// -keys
+	figs = figs.NewMap(argKeys, map[string]string{}, "override key bindings, e.g. 'search=/ f,back=esc backspace' (press ? in the TUI for the actions)")
+	figs = figs.WithValidator(argKeys, assureKeyOverrides)
+
 	// -v (version)
 	figs = figs.NewBool(argVersion, false, "print version")
 
@@ -4,13 +4,17 @@ const (
 	appName   string = "tf-reconcile-reader"
 	appAuthor string = "andrei@merlescu.net"
 
-	envConfigFile string = "CONFIG_FILE"
-	envVimMode    string = "FIGS_VIM_MODE"
-	envExecDir    string = "FIGS_EXEC_DIR"
-	envTfDir      string = "FIGS_TF_DIR"
-	envTfS3Bucket string = "FIGS_TF_S3_BUCKET"
-	envTfState    string = "FIGS_TF_STATE"
-	envGitHub     string = "FIGS_GITHUB"
+	envConfigFile    string = "CONFIG_FILE"
+	envConfigEnvFile string = "CONFIG_ENV_FILE"
+	envVimMode       string = "FIGS_VIM_MODE"
+	envExecDir       string = "FIGS_EXEC_DIR"
+	envTfDir         string = "FIGS_TF_DIR"
+	envTfS3Bucket    string = "FIGS_TF_S3_BUCKET"
+	envTfState       string = "FIGS_TF_STATE"
+	envGitHub        string = "FIGS_GITHUB"
+	envServeToken    string = "FIGS_SERVE_TOKEN"
+	envNotifyURL     string = "FIGS_NOTIFY_URL"
+	envTicketsToken  string = "FIGS_TICKETS_TOKEN"
 
 	argInputFile            string = "input"
 	argAliasInputFile       string = "i"
@@ -23,7 +27,116 @@ const (
 	argVimEnabled           string = "vi"
 	argSaveDir              string = "save"
 	argGitHub               string = "github"
+	argValidate             string = "validate"
+	argWatch                string = "watch"
+	argWatchInterval        string = "watch-interval"
+	argKeys                 string = "keys"
+	argTriage               string = "triage"
+	argBaseline             string = "baseline"
+	argGenerateBaseline     string = "generate-baseline"
+	argFailOn               string = "fail-on"
+	argHistory              string = "history"
+	argIngest               string = "ingest"
+	argTrend                string = "trend"
+	argRedact               string = "redact"
+	argNoRedact             string = "no-redact"
+	argProfile              string = "profile"
+	argProfiles             string = "profiles"
+	argTerraform            string = "terraform"
+	argPlan                 string = "plan"
+	argRefactor             string = "refactor"
+	argReplay               string = "replay"
+	argServe                string = "serve"
+	argServeToken           string = "serve-token"
+	argMetrics              string = "metrics"
+	argMetricsFile          string = "metrics-file"
+	argNotify               string = "notify"
+	argNotifyURL            string = "notify-url"
+	argNotifyTemplate       string = "notify-template"
+	argNotifyBaseline       string = "notify-baseline"
+	argNotifyDryRun         string = "notify-dry-run"
+	argTickets              string = "tickets"
+	argTicketsPush          string = "tickets-push"
+	argTicketsToken         string = "tickets-token"
+	argTicketsProject       string = "tickets-project"
+	argTicketsLedger        string = "tickets-ledger"
 
+	// oldestReportVersion is assumed for reports that predate the version field.
+	oldestReportVersion string = "v0.0.0"
+
+	// planned actions of the -plan file
+	planNoOp    string = "no-op"
+	planCreate  string = "create"
+	planUpdate  string = "update"
+	planDelete  string = "delete"
+	planReplace string = "replace"
+
+	// planPreviewImportFile and planPreviewGeneratedFile are written to the scratch copy of the
+	// terraform directory by the import preview.
+	planPreviewImportFile    string = "zz_tf_reconcile_reader_import.tf"
+	planPreviewGeneratedFile string = "zz_tf_reconcile_reader_generated.tf"
+
+	// refactorSuffix replaces .json in the name of the file the TUI writes the moved and removed blocks to.
+	refactorSuffix string = ".refactor.tf"
+
+	// replaySuffix replaces .json in the name of the replay script the TUI writes.
+	replaySuffix string = ".replay.sh"
+
+	// serveDefaultLimit and serveMaxLimit bound the page size of /api/logs.
+	serveDefaultLimit int = 50
+	serveMaxLimit     int = 500
+
+	// metricsPrefix starts the name of every exported metric.
+	metricsPrefix string = "tf_reconcile_reader_"
+
+	// notifyMaxItems caps the DANGEROUS results and failed commands listed per report in a
+	// notification; chat messages have a size limit.
+	notifyMaxItems int = 10
+
+	// formats of -tickets
+	ticketsJira   string = "jira"
+	ticketsGitHub string = "github"
+
+	// annotationsSuffix replaces .json in the name of the sidecar file that holds the triage annotations.
+	annotationsSuffix string = ".annotations.json"
+
+	// names of the history store and the ticket ledger in the -save directory
+	historyFileName       string = "history.json"
+	ticketsLedgerFileName string = "tickets.json"
+
+	// triage statuses of an annotation, and the filter for items without one
+	statusDone     string = "done"
+	statusFollowUp string = "follow-up"
+	statusIgnore   string = "ignore"
+	triageUnmarked string = "unmarked"
+
+	// trendListLimit is how many flapping resources and failing commands the trend lists per state.
+	trendListLimit int = 25
+
+	// logBatchSize is how many execution logs are decoded before the TUI is handed a batch.
+	logBatchSize int = 250
+
+	// matchMarker marks where a search match starts while the detail view is wrapped. It has no
+	// width, so it does not move the line breaks.
+	matchMarker string = "\u200b"
+)
+
+const (
+	sortByReportOrder logSortKey = iota
+	sortByExitCode
+	sortByVerb
+	sortBySource
+	sortByAddress
+	logSortKeys // number of sort orders, for cycling
+)
+
+const (
+	issueNote issueSeverity = iota
+	issueWarning
+	issueError
+)
+
+const (
 	viewMain viewState = iota
 	viewBackup
 	viewBackupDetail
@@ -36,4 +149,15 @@ const (
 	viewRunningCommand
 	viewExecutionLogDetail
 	viewCommandRunner
+	viewEnvironments
+	viewEnvironmentSummary
+	viewSearch
+	viewLogTree
+	viewLinkedLogs
+	viewResource
+	viewHelp
+	viewAnnotate
+	viewTrend
+	viewProfiles
+	viewPlanPreview
 )
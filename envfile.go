@@ -0,0 +1,176 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// envFile returns the file that config edits are saved to: $CONFIG_ENV_FILE, or
+// tf-reconcile-reader.env next to the config file.
+func envFile() string {
+	if v, ok := os.LookupEnv(envConfigEnvFile); ok && v != "" {
+		return v
+	}
+	return filepath.Join(filepath.Dir(configFile()), appName+".env")
+}
+
+// shellQuote quotes a value for a POSIX shell. The value goes in single quotes, and a single quote
+// inside it closes the quoting, adds an escaped quote and opens it again.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// shellUnquote reverses shellQuote, and also reads double-quoted and unquoted values the way a
+// shell would, without expanding variables.
+func shellUnquote(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated single quote")
+			}
+			b.WriteString(s[i+1 : i+1+end])
+			i += end + 1
+		case '"':
+			i++
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) && strings.IndexByte("\"\\$`", s[i+1]) >= 0 {
+					i++
+				}
+				b.WriteByte(s[i])
+			}
+			if i >= len(s) {
+				return "", fmt.Errorf("unterminated double quote")
+			}
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), nil
+}
+
+// parseEnvLine reads KEY=value or export KEY=value. Blank lines and comments are not assignments.
+func parseEnvLine(line string) (string, string, bool, error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false, nil
+	}
+	line = strings.TrimPrefix(line, "export ")
+	key, raw, ok := strings.Cut(line, "=")
+	if !ok || strings.TrimSpace(key) == "" {
+		return "", "", false, fmt.Errorf("expected KEY=value, got %q", line)
+	}
+	value, err := shellUnquote(raw)
+	if err != nil {
+		return "", "", false, fmt.Errorf("%s: %w", key, err)
+	}
+	return strings.TrimSpace(key), value, true, nil
+}
+
+// readBeforeEnvFile reports whether a key is read before the env file loads, so saving it there
+// could never take effect: the config file and the env file locate the env file itself.
+func readBeforeEnvFile(key string) bool {
+	return key == envConfigFile || key == envConfigEnvFile
+}
+
+// loadEnvFile applies the saved config edits to the environment. A variable the shell exports wins
+// over the file, with a warning when they differ. A missing file is fine.
+func loadEnvFile(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for n, line := range strings.Split(string(data), "\n") {
+		key, value, ok, err := parseEnvLine(line)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", path, n+1, err)
+		}
+		if !ok {
+			continue
+		}
+		if current, set := os.LookupEnv(key); set {
+			if current != value {
+				log.Printf("%s:%d: %s is exported in the shell, ignoring the saved value", path, n+1, key)
+			}
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveEnvValue writes key to the env file, replacing an earlier value and keeping every other
+// line. The file can be sourced by a shell as well.
+func saveEnvValue(path, key, value string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	assignment := fmt.Sprintf("export %s=%s", key, shellQuote(value))
+	var lines []string
+	replaced := false
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		if k, _, ok, _ := parseEnvLine(line); ok && k == key {
+			if !replaced {
+				lines = append(lines, assignment)
+				replaced = true
+			}
+			continue
+		}
+		if line != "" || len(lines) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	if !replaced {
+		lines = append(lines, assignment)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// the values may be credentials, keep them to the owner
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// saveConfigEdit saves the edited value to the env file and applies it to the running process, so
+// the next command runs with it, e.g. a new FIGS_TF_DIR or FIGS_TF_STATE.
+func (m *model) saveConfigEdit() tea.Cmd {
+	key, value := m.activeConfigItem.key, m.textInput.Value()
+	path := envFile()
+	if err := saveEnvValue(path, key, value); err != nil {
+		m.setNotification(fmt.Sprintf("Failed to save %s: %v", key, err), true)
+		return m.clearNotificationAfter(3 * time.Second)
+	}
+	if err := os.Setenv(key, value); err != nil {
+		m.setNotification(fmt.Sprintf("Saved %s to %s, but could not apply it: %v", key, path, err), true)
+		return m.clearNotificationAfter(3 * time.Second)
+	}
+	m.state = m.popView()
+	selected := m.configList.Index()
+	m.loadConfigList()
+	m.configList.Select(selected)
+	m.setNotification(fmt.Sprintf("Saved %s to %s", key, path), false)
+	return m.clearNotificationAfter(3 * time.Second)
+}
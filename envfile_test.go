@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadEnvFileKeepsShellExports(t *testing.T) {
+	path := filepath.Join(t.TempDir(), appName+".env")
+	if err := saveEnvValue(path, "FIGS_TEST_SHELL", "from file"); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveEnvValue(path, "FIGS_TEST_FILE", "it's saved"); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("FIGS_TEST_SHELL", "from shell")
+	t.Setenv("FIGS_TEST_FILE", "")
+	if err := os.Unsetenv("FIGS_TEST_FILE"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := loadEnvFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := os.Getenv("FIGS_TEST_SHELL"); got != "from shell" {
+		t.Errorf("FIGS_TEST_SHELL = %q, want the shell's value", got)
+	}
+	if got := os.Getenv("FIGS_TEST_FILE"); got != "it's saved" {
+		t.Errorf("FIGS_TEST_FILE = %q, want the saved value", got)
+	}
+}
+
+func TestShellQuoteRoundTrip(t *testing.T) {
+	_, shErr := exec.LookPath("sh")
+	for _, value := range []string{
+		"", "plain", "two words", "it's", "'''", `say "hi"`, "$HOME ${USER} $(id) `id`",
+		`back\slash\`, `\'`, "line one\nline two\n", "tab\there", "naïve 東京", "-n", "*",
+	} {
+		quoted := shellQuote(value)
+		got, err := shellUnquote(quoted)
+		if err != nil || got != value {
+			t.Errorf("shellUnquote(shellQuote(%q)) = %q, %v", value, got, err)
+		}
+		if shErr != nil {
+			continue
+		}
+		// a shell reads the quoted value back the same way
+		out, err := exec.Command("sh", "-c", "printf '%s' "+quoted).Output()
+		if err != nil || string(out) != value {
+			t.Errorf("sh reads %s as %q, %v, want %q", quoted, out, err, value)
+		}
+	}
+}
+
+func TestShellUnquote(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{`plain`, "plain"},
+		{`a\ b`, "a b"},
+		{`"a \"b\" \$HOME \\ \x \` + "`" + `"`, "a \"b\" $HOME \\ \\x `"},
+		{`"$HOME"`, "$HOME"},
+		{`'single'"double"bare`, "singledoublebare"},
+		{"'line\nbreak'", "line\nbreak"},
+		{`'it'\''s'`, "it's"},
+	} {
+		if got, err := shellUnquote(tc.in); err != nil || got != tc.want {
+			t.Errorf("shellUnquote(%s) = %q, %v, want %q", tc.in, got, err, tc.want)
+		}
+	}
+	for _, in := range []string{`'open`, `"open`, `"open\"`} {
+		if got, err := shellUnquote(in); err == nil {
+			t.Errorf("shellUnquote(%s) = %q, want an error", in, got)
+		}
+	}
+}
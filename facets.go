@@ -0,0 +1,144 @@
+package main
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// String returns the name of the sort order shown in the header.
+func (k logSortKey) String() string {
+	switch k {
+	case sortByExitCode:
+		return "exit code"
+	case sortByVerb:
+		return "command"
+	case sortBySource:
+		return "source"
+	case sortByAddress:
+		return "address"
+	default:
+		return "report order"
+	}
+}
+
+// commandVerb returns the terraform subcommand of a command, e.g. "import", "state rm" or "plan".
+// Commands that do not run terraform return their first word.
+func commandVerb(command string) string {
+	fields := strings.Fields(command)
+	for i, field := range fields {
+		if filepath.Base(field) != "terraform" {
+			continue
+		}
+		var verb []string
+		for _, arg := range fields[i+1:] {
+			if strings.HasPrefix(arg, "-") {
+				continue
+			}
+			verb = append(verb, arg)
+			if verb[0] != "state" || len(verb) == 2 {
+				break
+			}
+		}
+		return strings.Join(verb, " ")
+	}
+	if len(fields) == 0 {
+		return ""
+	}
+	return filepath.Base(fields[0])
+}
+
+// active lists the enabled facets for the header.
+func (f logFacets) active() []string {
+	var names []string
+	if f.failedOnly {
+		names = append(names, "failed only")
+	}
+	if f.githubOnly {
+		names = append(names, "source = GITHUB ACTIONS")
+	}
+	if f.hasStderr {
+		names = append(names, "has stderr")
+	}
+	return names
+}
+
+// matches reports whether a log passes every enabled facet.
+func (f logFacets) matches(log CommandExecutionLog) bool {
+	if f.failedOnly && log.ExitCode == 0 {
+		return false
+	}
+	if f.githubOnly && log.Source != "GITHUB ACTIONS" {
+		return false
+	}
+	if f.hasStderr && strings.TrimSpace(log.Stderr) == "" {
+		return false
+	}
+	return true
+}
+
+// visibleLogs applies the search, the facets, the triage filter and the sort order to the execution logs of the
+// active report. It also returns the number of search matches in the visible logs.
+func (m model) visibleLogs() ([]CommandExecutionLog, int) {
+	logs, matches := m.filterLogs(0)
+	sortLogs(logs, m.logLess())
+	return logs, matches
+}
+
+// filterLogs applies the search, the facets and the triage filter to the execution logs of the
+// active report from index from on, and returns them in report order with their number of search
+// matches.
+func (m model) filterLogs(from int) ([]CommandExecutionLog, int) {
+	var logs []CommandExecutionLog
+	matches := 0
+	for i := from; i < len(m.report.ExecutionLogs); i++ {
+		log := m.report.ExecutionLogs[i]
+		if !m.facets.matches(log) || !matchesTriage(m.triage, m.logMark(log)) {
+			continue
+		}
+		if m.search.re != nil {
+			n := countLogMatches(m.displayedLogAt(i), m.search.re)
+			if n == 0 {
+				continue
+			}
+			matches += n
+		}
+		logs = append(logs, log)
+	}
+	return logs, matches
+}
+
+// logLess orders execution logs by the sort key, nil for report order.
+func (m model) logLess() func(a, b CommandExecutionLog) bool {
+	switch m.logSort {
+	case sortByExitCode:
+		return func(a, b CommandExecutionLog) bool { return a.ExitCode > b.ExitCode } // failures first
+	case sortByVerb:
+		return func(a, b CommandExecutionLog) bool { return commandVerb(a.Command) < commandVerb(b.Command) }
+	case sortBySource:
+		return func(a, b CommandExecutionLog) bool { return a.Source < b.Source }
+	case sortByAddress:
+		return func(a, b CommandExecutionLog) bool { return a.TerraformAddress < b.TerraformAddress }
+	}
+	return nil
+}
+
+// sortLogs sorts logs stably, so logs that compare equal stay in report order.
+func sortLogs(logs []CommandExecutionLog, less func(a, b CommandExecutionLog) bool) {
+	if less != nil {
+		sort.SliceStable(logs, func(i, j int) bool { return less(logs[i], logs[j]) })
+	}
+}
+
+// logListStatus describes the sort order, facets and triage filter for the header, empty when none is set.
+func (m model) logListStatus() string {
+	var parts []string
+	if m.logSort != sortByReportOrder {
+		parts = append(parts, "sort: "+m.logSort.String())
+	}
+	parts = append(parts, m.facets.active()...)
+	if m.triage != "" {
+		parts = append(parts, "triage: "+m.triage)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,425 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+// historyPath returns the -history store, by default history.json in the -save directory.
+func historyPath() string {
+	if path := *figs.String(argHistory); path != "" {
+		return path
+	}
+	return filepath.Join(*figs.String(argSaveDir), historyFileName)
+}
+
+// loadHistory reads the history store. A missing store is empty.
+func loadHistory(path string) (*historyStore, error) {
+	h := &historyStore{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return h, nil
+}
+
+// save writes the history store through a temporary file.
+func (h *historyStore) save() error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return err
+	}
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, h.path)
+}
+
+// runKey identifies a report by the state it was run against, so the same run is stored once. A
+// report without a state checksum says nothing about its state, so the digest of its content
+// identifies it instead.
+func runKey(checksum string, version uint64, digest string) string {
+	if checksum == "" {
+		return "sha256:" + digest
+	}
+	return fmt.Sprintf("%s@%d", checksum, version)
+}
+
+// has reports whether the store already holds a run with the key.
+func (h *historyStore) has(key string) bool {
+	for _, run := range h.Runs {
+		if runKey(run.StateChecksum, run.StateVersion, run.Digest) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// hasObject reports whether a run was ingested from the S3 object, named with its ETag, so an
+// object that did not change is not downloaded again.
+func (h *historyStore) hasObject(object string) bool {
+	for _, run := range h.Runs {
+		if run.Object == object {
+			return true
+		}
+	}
+	return false
+}
+
+// fileDigest returns the hex SHA-256 of a file.
+func fileDigest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	sum := sha256.New()
+	if _, err := io.Copy(sum, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// newHistoryRun condenses a report into what the trends need: counts, the category of every
+// resource and the commands that failed.
+func newHistoryRun(source string, at time.Time, report *JSONOutput) historyRun {
+	run := historyRun{
+		Source:        source,
+		State:         report.State,
+		StateChecksum: report.StateChecksum,
+		StateVersion:  report.StateVersion,
+		ReportTime:    at.UTC(),
+		Ingested:      time.Now().UTC(),
+		Counts:        make(map[string]int),
+		Resources:     make(map[string]string),
+		Failed:        make(map[string]int),
+	}
+	for _, cat := range resultCategories {
+		results := report.Results.GetCategory(cat)
+		if len(results) > 0 {
+			run.Counts[cat] = len(results)
+		}
+		for _, item := range results {
+			run.Resources[item.Resource] = cat
+		}
+	}
+	for _, log := range report.ExecutionLogs {
+		if log.ExitCode != 0 {
+			run.Failed[log.Command]++
+		}
+	}
+	return run
+}
+
+// ingest adds the reports matched by source to the store: a report file, a directory, a glob or
+// an s3://bucket/prefix. Reports of a state checksum and version already in the store are skipped,
+// as are reports without a checksum whose content is stored and S3 objects ingested before.
+func (h *historyStore) ingest(ctx context.Context, source string) (added, skipped int, err error) {
+	files, skipped, err := historyInputs(ctx, source, h)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, file := range files {
+		meta, _, err := loadReportMeta(file.path)
+		if err != nil {
+			return added, skipped, fmt.Errorf("%s: %w", file.source, err)
+		}
+		var digest string
+		if meta.StateChecksum == "" {
+			if digest, err = fileDigest(file.path); err != nil {
+				return added, skipped, fmt.Errorf("%s: %w", file.source, err)
+			}
+		}
+		if h.has(runKey(meta.StateChecksum, meta.StateVersion, digest)) {
+			skipped++
+			continue
+		}
+		report, err := loadReportData(file.path)
+		if err != nil {
+			return added, skipped, fmt.Errorf("%s: %w", file.source, err)
+		}
+		run := newHistoryRun(file.source, file.at, report)
+		run.Digest, run.Object = digest, file.object
+		h.Runs = append(h.Runs, run)
+		added++
+	}
+	sort.SliceStable(h.Runs, func(i, j int) bool {
+		if !h.Runs[i].ReportTime.Equal(h.Runs[j].ReportTime) {
+			return h.Runs[i].ReportTime.Before(h.Runs[j].ReportTime)
+		}
+		return h.Runs[i].StateVersion < h.Runs[j].StateVersion
+	})
+	return added, skipped, nil
+}
+
+// historyInputs resolves an -ingest source into local report files, downloading an S3 prefix into
+// the -save directory first. Objects the store already holds a run of are not downloaded and only
+// counted as skipped.
+func historyInputs(ctx context.Context, source string, h *historyStore) ([]historyInput, int, error) {
+	if !strings.HasPrefix(source, "s3://") {
+		paths, err := resolveReportInputs(source)
+		if err != nil {
+			return nil, 0, err
+		}
+		inputs := make([]historyInput, 0, len(paths))
+		for _, path := range paths {
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, 0, err
+			}
+			inputs = append(inputs, historyInput{source: path, path: path, at: info.ModTime()})
+		}
+		return inputs, 0, nil
+	}
+
+	bucket, prefix, _ := strings.Cut(strings.TrimPrefix(source, "s3://"), "/")
+	if bucket == "" {
+		return nil, 0, fmt.Errorf("invalid S3 prefix: %s", source)
+	}
+	if err := initS3Client(ctx); err != nil {
+		return nil, 0, err
+	}
+	var inputs []historyInput
+	skipped := 0
+	pages := s3.NewListObjectsV2Paginator(s3Client, &s3.ListObjectsV2Input{Bucket: aws.String(bucket), Prefix: aws.String(prefix)})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to list %s: %w", source, err)
+		}
+		for _, object := range page.Contents {
+			key := aws.ToString(object.Key)
+			if !strings.HasSuffix(key, ".json") || !isReportFile(key) {
+				continue
+			}
+			// nightly reports often share a file name, so keep the key's directories, but never
+			// let a key such as ../../x.json write outside the download directory
+			if !filepath.IsLocal(key) {
+				return nil, 0, fmt.Errorf("refusing to download s3://%s/%s: the key leaves the download directory", bucket, key)
+			}
+			s3Path := fmt.Sprintf("s3://%s/%s", bucket, key)
+			id := s3Path + "#" + strings.Trim(aws.ToString(object.ETag), `"`)
+			if h.hasObject(id) {
+				skipped++
+				continue
+			}
+			dir := filepath.Join(*figs.String(argSaveDir), "history", bucket, filepath.Dir(key))
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return nil, 0, err
+			}
+			path, err := downloadS3File(ctx, s3Path, dir)
+			if err != nil {
+				return nil, 0, err
+			}
+			inputs = append(inputs, historyInput{source: s3Path, path: path, object: id, at: aws.ToTime(object.LastModified)})
+		}
+	}
+	if len(inputs) == 0 && skipped == 0 {
+		return nil, 0, fmt.Errorf("no reports under %s", source)
+	}
+	return inputs, skipped, nil
+}
+
+// historyTrends finds the flapping resources and repeatedly failing commands in runs of one state,
+// oldest first.
+func historyTrends(runs []historyRun) ([]flappingResource, []failingCommand) {
+	resources := make(map[string]bool)
+	for _, run := range runs {
+		for resource := range run.Resources {
+			resources[resource] = true
+		}
+	}
+	var flapping []flappingResource
+	for _, resource := range sortedKeys(resources) {
+		f := flappingResource{resource: resource}
+		for i, run := range runs {
+			cat, ok := run.Resources[resource]
+			if !ok {
+				cat = "-"
+			}
+			if i > 0 && cat != f.categories[i-1] {
+				f.changes++
+			}
+			f.categories = append(f.categories, cat)
+		}
+		if f.changes >= 2 {
+			flapping = append(flapping, f)
+		}
+	}
+	sort.SliceStable(flapping, func(i, j int) bool { return flapping[i].changes > flapping[j].changes })
+
+	byCommand := make(map[string]*failingCommand)
+	for _, run := range runs {
+		for command, n := range run.Failed {
+			if byCommand[command] == nil {
+				byCommand[command] = &failingCommand{command: command}
+			}
+			byCommand[command].runs++
+			byCommand[command].failures += n
+		}
+	}
+	var failing []failingCommand
+	for _, command := range sortedKeys(byCommand) {
+		if c := byCommand[command]; c.runs >= 2 {
+			failing = append(failing, *c)
+		}
+	}
+	sort.SliceStable(failing, func(i, j int) bool { return failing[i].runs > failing[j].runs })
+	return flapping, failing
+}
+
+// renderTrend renders the history per state: category counts per run, flapping resources and
+// commands that keep failing.
+func renderTrend(h *historyStore, width int) string {
+	var b strings.Builder
+	if len(h.Runs) == 0 {
+		b.WriteString(helpStyle.Render(fmt.Sprintf("No history in %s yet, add reports with -%s <dir|glob|s3://bucket/prefix>", h.path, argIngest)) + "\n")
+		return b.String()
+	}
+	byState := make(map[string][]historyRun)
+	for _, run := range h.Runs {
+		byState[run.State] = append(byState[run.State], run)
+	}
+	for _, state := range sortedKeys(byState) {
+		runs := byState[state]
+		b.WriteString(titleStyle.Render(fmt.Sprintf("State %s (%d runs)", orDash(state), len(runs))) + "\n\n")
+		b.WriteString(fmt.Sprintf("%-17s %8s", "REPORT TIME", "VERSION"))
+		for _, cat := range resultCategories {
+			b.WriteString(fmt.Sprintf(" %9s", shortCategory(cat)))
+		}
+		b.WriteString(fmt.Sprintf(" %7s\n", "FAILED"))
+		for _, run := range runs {
+			failed := 0
+			for _, n := range run.Failed {
+				failed += n
+			}
+			b.WriteString(fmt.Sprintf("%-17s %8d", run.ReportTime.Local().Format("2006-01-02 15:04"), run.StateVersion))
+			for _, cat := range resultCategories {
+				b.WriteString(fmt.Sprintf(" %9d", run.Counts[cat]))
+			}
+			b.WriteString(fmt.Sprintf(" %7d\n", failed))
+		}
+
+		flapping, failing := historyTrends(runs)
+		b.WriteString("\n" + titleStyle.Render(fmt.Sprintf("Flapping resources (%d)", len(flapping))) + "\n")
+		if len(flapping) == 0 {
+			b.WriteString(helpStyle.Render("No resource changed category more than once.") + "\n")
+		}
+		for i, f := range flapping {
+			if i == trendListLimit {
+				b.WriteString(helpStyle.Render(fmt.Sprintf("... and %d more", len(flapping)-i)) + "\n")
+				break
+			}
+			b.WriteString(truncateRunes(f.resource, width) + "\n")
+			b.WriteString(wordwrap.String(fmt.Sprintf("    %d changes: %s", f.changes, strings.Join(f.categories, " → ")), width) + "\n")
+		}
+		b.WriteString("\n" + titleStyle.Render(fmt.Sprintf("Commands that keep failing (%d)", len(failing))) + "\n")
+		if len(failing) == 0 {
+			b.WriteString(helpStyle.Render("No command failed in more than one run.") + "\n")
+		}
+		for i, c := range failing {
+			if i == trendListLimit {
+				b.WriteString(helpStyle.Render(fmt.Sprintf("... and %d more", len(failing)-i)) + "\n")
+				break
+			}
+			b.WriteString(truncateRunes(c.command, width) + "\n")
+			b.WriteString(fmt.Sprintf("    %s, %d failures\n", errorStyle.Render(fmt.Sprintf("failed in %d of %d runs", c.runs, len(runs))), c.failures))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// shortCategory abbreviates the long category names for the trend table.
+func shortCategory(category string) string {
+	switch category {
+	case "POTENTIAL_IMPORT":
+		return "IMPORT"
+	case "REGION_MISMATCH":
+		return "REGION"
+	}
+	return category
+}
+
+// runHistory ingests -ingest into the history store and prints the trends for -trend.
+func runHistory() error {
+	h, err := loadHistory(historyPath())
+	if err != nil {
+		return err
+	}
+	if source := *figs.String(argIngest); source != "" {
+		added, skipped, err := h.ingest(context.Background(), source)
+		if added > 0 {
+			if saveErr := h.save(); saveErr != nil {
+				return saveErr
+			}
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "%s: added %d run(s), skipped %d already stored\n", h.path, added, skipped)
+		if err != nil {
+			return err
+		}
+	}
+	if *figs.Bool(argTrend) {
+		fmt.Print(renderTrend(h, 120))
+	}
+	return nil
+}
+
+// showTrend opens the trend view of the history store.
+func (m *model) showTrend() tea.Cmd {
+	m.state = m.pushView(viewTrend)
+	m.renderTrendView()
+	return nil
+}
+
+func (m *model) renderTrendView() {
+	h, err := loadHistory(historyPath())
+	if err != nil {
+		m.viewPort.SetContent(errorStyle.Render(fmt.Sprintf("Could not read the history: %v", err)))
+		return
+	}
+	m.viewPort.SetContent(renderTrend(h, m.viewPort.Width))
+	m.viewPort.GotoTop()
+}
+
+func (m model) updateTrendView(msg tea.Msg) (model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, m.keys.Top):
+			m.viewPort.GotoTop()
+			return m, nil
+		case key.Matches(msg, m.keys.Bottom):
+			m.viewPort.GotoBottom()
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.viewPort, cmd = m.viewPort.Update(msg)
+	return m, cmd
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIngestSkipsStoredRuns(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"a", "b", "c", "d"} {
+		if err := os.Mkdir(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// two runs without a state checksum and of the same state version are different runs
+	writeReport(t, filepath.Join(dir, "a"), "prod", JSONOutput{State: "s3://b/prod.tfstate", StateVersion: 3, Version: "v1.3.0",
+		Results: JSONResults{OkResults: []JSONResultItem{{Resource: "aws_s3_bucket.a", Kind: "aws_s3_bucket"}}}})
+	writeReport(t, filepath.Join(dir, "b"), "prod", JSONOutput{State: "s3://b/prod.tfstate", StateVersion: 3, Version: "v1.3.0",
+		Results: JSONResults{ErrorResults: []JSONResultItem{{Resource: "aws_s3_bucket.a", Kind: "aws_s3_bucket"}}}})
+	writeReport(t, filepath.Join(dir, "c"), "prod", JSONOutput{State: "s3://b/prod.tfstate", StateChecksum: "abc", StateVersion: 4, Version: "v1.3.0"})
+	writeReport(t, filepath.Join(dir, "d"), "prod", JSONOutput{State: "s3://b/prod.tfstate", StateChecksum: "abc", StateVersion: 4, Version: "v1.3.0"})
+
+	h := &historyStore{path: filepath.Join(dir, "history.json")}
+	added, skipped, err := h.ingest(context.Background(), filepath.Join(dir, "*", "report.prod.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != 3 || skipped != 1 {
+		t.Errorf("first ingest added %d and skipped %d, want 3 and 1", added, skipped)
+	}
+	for _, run := range h.Runs {
+		if (run.StateChecksum == "") == (run.Digest == "") {
+			t.Errorf("run %s has checksum %q and digest %q", run.Source, run.StateChecksum, run.Digest)
+		}
+	}
+
+	added, skipped, err = h.ingest(context.Background(), filepath.Join(dir, "*", "report.prod.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != 0 || skipped != 4 {
+		t.Errorf("second ingest added %d and skipped %d, want 0 and 4", added, skipped)
+	}
+
+	// a report rewritten with other content is a new run
+	writeReport(t, filepath.Join(dir, "a"), "prod", JSONOutput{State: "s3://b/prod.tfstate", StateVersion: 3, Version: "v1.3.1"})
+	if added, _, err = h.ingest(context.Background(), filepath.Join(dir, "a", "report.prod.json")); err != nil || added != 1 {
+		t.Errorf("ingest of changed content added %d: %v", added, err)
+	}
+}
+
+func TestHistoryHasObject(t *testing.T) {
+	h := &historyStore{Runs: []historyRun{{Source: "s3://b/r/report.prod.json", Object: "s3://b/r/report.prod.json#e1"}}}
+	if !h.hasObject("s3://b/r/report.prod.json#e1") {
+		t.Error("the ingested object is not known")
+	}
+	if h.hasObject("s3://b/r/report.prod.json#e2") {
+		t.Error("an object with another ETag is known")
+	}
+}
@@ -9,38 +9,94 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-func (i mainItem) Title() string       { return i.log.Command }
-func (i mainItem) Description() string { return fmt.Sprintf("Exit: %d", i.log.ExitCode) }
+func (i mainItem) Title() string { return i.log.Command }
+func (i mainItem) Description() string {
+	parts := []string{fmt.Sprintf("Exit: %d", i.log.ExitCode)}
+	for _, part := range []string{commandVerb(i.log.Command), i.log.Source, i.log.TerraformAddress} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " | ")
+}
 func (i mainItem) FilterValue() string { return i.log.Command }
 
 func (i backupItem) Title() string       { return i.key }
 func (i backupItem) Description() string { return i.val }
 func (i backupItem) FilterValue() string { return i.key }
 
-func (i resultCategoryItem) Title() string       { return i.name }
-func (i resultCategoryItem) Description() string { return fmt.Sprintf("%d items", i.count) }
+func (i resultCategoryItem) Title() string { return i.name }
+func (i resultCategoryItem) Description() string {
+	desc := fmt.Sprintf("%d items", i.count)
+	if i.suppressed > 0 {
+		desc = fmt.Sprintf("%d items (%d accepted by the baseline)", i.count, i.suppressed)
+	}
+	if i.conflicts > 0 {
+		desc += errorStyle.Render(fmt.Sprintf(" %d plan conflicts", i.conflicts))
+	}
+	return desc
+}
 func (i resultCategoryItem) FilterValue() string { return i.name }
 
 func (i configItem) Title() string       { return i.key }
 func (i configItem) Description() string { return i.val }
 func (i configItem) FilterValue() string { return i.key }
 
+func (i profileItem) Title() string {
+	if i.Name == "" {
+		return "(environment)"
+	}
+	return i.Name
+}
+func (i profileItem) Description() string {
+	if i.Name == "" {
+		return fmt.Sprintf("%s and %s as exported", envTfDir, envTfState)
+	}
+	tfDir, tfState := i.dirAndState()
+	parts := []string{"dir " + orDash(tfDir), "state " + orDash(tfState)}
+	if i.AWSProfile != "" {
+		parts = append(parts, "aws "+i.AWSProfile)
+	}
+	if region := i.expectedRegion(); region != "" {
+		parts = append(parts, "region "+region)
+	}
+	return strings.Join(parts, " | ")
+}
+func (i profileItem) FilterValue() string { return i.Name }
+
 func (d itemDelegate) Height() int                               { return 1 }
 func (d itemDelegate) Spacing() int                              { return 0 }
 func (d itemDelegate) Update(msg tea.Msg, m *list.Model) tea.Cmd { return nil }
 func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
 	var title string
 	switch item := listItem.(type) {
+	case environmentItem:
+		title = fmt.Sprintf("%-12s %s", item.Title(), item.Description())
 	case mainItem:
 		title = item.Title()
+		if !item.placeholder {
+			title = fmt.Sprintf("%s%s  %s", item.mark.badge(), title, helpStyle.Render(item.Description()))
+		}
+	case logTreeItem:
+		title = item.render()
 	case backupItem:
 		title = fmt.Sprintf("%-25s = %s", item.Title(), item.Description())
 	case resultCategoryItem:
 		title = fmt.Sprintf("%-25s %s", item.Title(), item.Description())
-	case JSONResultItem:
-		title = item.Title()
+	case resultItem:
+		title = item.mark.badge() + planBadge(item.planned, item.conflict) + item.Title()
+		if item.suppressed {
+			title = helpStyle.Render("[baseline] ") + title
+		}
 	case configItem:
 		title = fmt.Sprintf("%s = %s", item.Title(), item.Description())
+	case profileItem:
+		title = fmt.Sprintf("%-15s %s", item.Title(), helpStyle.Render(item.Description()))
+		if item.active {
+			title = "* " + title
+		} else {
+			title = "  " + title
+		}
 	default:
 		return
 	}
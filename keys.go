@@ -0,0 +1,262 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+// bind creates a binding whose help shows every key, e.g. "enter/l".
+func bind(desc string, keys ...string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(helpKeys(keys), desc))
+}
+
+// helpKeys renders keys the way the footer shows them.
+func helpKeys(keys []string) string {
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		switch k {
+		case "up":
+			names[i] = "↑"
+		case "down":
+			names[i] = "↓"
+		case "left":
+			names[i] = "←"
+		case "right":
+			names[i] = "→"
+		case " ":
+			names[i] = "space"
+		default:
+			names[i] = k
+		}
+	}
+	return strings.Join(names, "/")
+}
+
+// newKeyMap returns the default bindings. Vim mode adds h to go back and j/k to wrap the list.
+func newKeyMap(vim bool) keyMap {
+	back, up, down := []string{"esc"}, []string{"up"}, []string{"down"}
+	if vim {
+		back, up, down = append(back, "h"), append(up, "k"), append(down, "j")
+	}
+	return keyMap{
+		ForceQuit: bind("Quit", "ctrl+c"),
+		Quit:      bind("Quit", "q"),
+		Back:      bind("Back", back...),
+		Help:      bind("Help", "?"),
+		Root:      bind("Go to Start", "~", "home"),
+		Up:        bind("Up", up...),
+		Down:      bind("Down", down...),
+
+		Open:   bind("Open", "enter"),
+		Select: bind("Open", "s", "l"),
+
+		Search:     bind("Search", "f"),
+		Group:      bind("Group", "g"),
+		Sort:       bind("Sort", "o"),
+		FailedOnly: bind("Failed Only", "F"),
+		GitHubOnly: bind("GitHub Only", "A"),
+		HasStderr:  bind("Has Stderr", "E"),
+		Backups:    bind("Backups", "b"),
+		Results:    bind("Results", "r"),
+		Configs:    bind("Configs", "c"),
+		Trend:      bind("Trend", "H"),
+		Profiles:   bind("Profiles", "P"),
+
+		Top:       bind("Top", "t"),
+		Bottom:    bind("Bottom", "b"),
+		NextMatch: bind("Next Match", "n"),
+		PrevMatch: bind("Prev Match", "N"),
+		Copy:      bind("Copy", "c"),
+		Edit:      bind("Edit & Copy Command", "e"),
+		Result:    bind("Result", "R"),
+		Resource:  bind("Resource", "a"),
+		Execute:   bind("Execute Command", "X"),
+		Preview:   bind("Preview Import", "p"),
+		Logs:      bind("Execution Logs", "L"),
+
+		Toggle:      bind("Expand/Collapse", " ", "right", "l"),
+		Collapse:    bind("Collapse", "left", "h"),
+		ExpandAll:   bind("Expand All", "+"),
+		CollapseAll: bind("Collapse All", "-"),
+		Summary:     bind("Summary", "s"),
+
+		ShowSuppressed: bind("Show Suppressed", "S"),
+
+		Mark:         bind("Mark", "m"),
+		Note:         bind("Note", "M"),
+		TriageFilter: bind("Triage Filter", "T"),
+		ExportTriage: bind("Write Triage Summary", "W"),
+
+		ExportRefactor: bind("Write moved/removed Blocks", "Y"),
+		ExportReplay:   bind("Write Replay Script", "Z"),
+
+		Submit: bind("Submit", "enter"),
+		Cancel: bind("Cancel", "esc"),
+	}
+}
+
+// byName maps the names accepted by -keys to the bindings they override.
+func (k *keyMap) byName() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"force-quit":    &k.ForceQuit,
+		"quit":          &k.Quit,
+		"back":          &k.Back,
+		"help":          &k.Help,
+		"root":          &k.Root,
+		"up":            &k.Up,
+		"down":          &k.Down,
+		"open":          &k.Open,
+		"select":        &k.Select,
+		"search":        &k.Search,
+		"group":         &k.Group,
+		"sort":          &k.Sort,
+		"failed-only":   &k.FailedOnly,
+		"github-only":   &k.GitHubOnly,
+		"has-stderr":    &k.HasStderr,
+		"backups":       &k.Backups,
+		"results":       &k.Results,
+		"configs":       &k.Configs,
+		"trend":         &k.Trend,
+		"profiles":      &k.Profiles,
+		"top":           &k.Top,
+		"bottom":        &k.Bottom,
+		"next-match":    &k.NextMatch,
+		"prev-match":    &k.PrevMatch,
+		"copy":          &k.Copy,
+		"edit":          &k.Edit,
+		"result":        &k.Result,
+		"resource":      &k.Resource,
+		"execute":       &k.Execute,
+		"preview":       &k.Preview,
+		"logs":          &k.Logs,
+		"toggle":        &k.Toggle,
+		"collapse":      &k.Collapse,
+		"expand-all":    &k.ExpandAll,
+		"collapse-all":  &k.CollapseAll,
+		"summary":       &k.Summary,
+		"suppressed":    &k.ShowSuppressed,
+		"mark":          &k.Mark,
+		"note":          &k.Note,
+		"triage":        &k.TriageFilter,
+		"export-triage": &k.ExportTriage,
+		"refactor":      &k.ExportRefactor,
+		"replay":        &k.ExportReplay,
+		"submit":        &k.Submit,
+		"cancel":        &k.Cancel,
+	}
+}
+
+// override replaces the keys of the named bindings. Each value lists the new keys separated by
+// spaces, e.g. {"search": "/ f", "back": "esc backspace"}. figtree keeps quotes in map values, so
+// quotes around a value are dropped rather than bound as keys.
+func (k *keyMap) override(overrides map[string]string) error {
+	bindings := k.byName()
+	for _, name := range sortedKeys(overrides) {
+		binding, ok := bindings[name]
+		if !ok {
+			return fmt.Errorf("-%s: unknown action %q, expected one of %s", argKeys, name, strings.Join(sortedKeys(bindings), ", "))
+		}
+		keys := strings.Fields(strings.Trim(overrides[name], `"'`))
+		if len(keys) == 0 {
+			return fmt.Errorf("-%s: no keys given for %q", argKeys, name)
+		}
+		binding.SetKeys(keys...)
+		binding.SetHelp(helpKeys(keys), binding.Help().Desc)
+	}
+	return nil
+}
+
+// loadKeyMap builds the keymap from -vi and the -keys overrides.
+func loadKeyMap() (keyMap, error) {
+	keys := newKeyMap(*figs.Bool(argVimEnabled))
+	if overrides := figs.Map(argKeys); overrides != nil {
+		if err := keys.override(*overrides); err != nil {
+			return keys, err
+		}
+	}
+	return keys, nil
+}
+
+// assureKeyOverrides is the figtree validator for -keys.
+func assureKeyOverrides(value interface{}) error {
+	var overrides map[string]string
+	switch v := value.(type) {
+	case map[string]string:
+		overrides = v
+	case *map[string]string:
+		if v != nil {
+			overrides = *v
+		}
+	}
+	keys := newKeyMap(false)
+	return keys.override(overrides)
+}
+
+// groups returns the bindings of the help overlay, section by section.
+func (k keyMap) groups() []keyGroup {
+	return []keyGroup{
+		{"Global", []key.Binding{k.Up, k.Down, k.Back, k.Root, k.Help, as(k.Quit, "Back, or Quit from the first view"), k.ForceQuit}},
+		{"Execution Logs", []key.Binding{k.Open, k.Select, k.Search, k.Group, k.Sort, k.FailedOnly, k.GitHubOnly, k.HasStderr, k.Backups, k.Results, k.Configs, as(k.Trend, "Trend of the -history store"), as(k.Profiles, "Switch the Profile Commands Run With"), as(k.ExportRefactor, "Write moved/removed Blocks of the state mv/rm Logs"), as(k.ExportReplay, "Write a Replay Script of the Listed Logs")}},
+		{"Detail Views", []key.Binding{k.Top, k.Bottom, k.NextMatch, k.PrevMatch, k.Copy, k.Edit, k.Result, k.Resource}},
+		{"Results", []key.Binding{k.Execute, as(k.Preview, "Plan the Import in a Scratch Copy"), k.Logs, k.Resource, as(k.ShowSuppressed, "Show/Hide Results Accepted by -baseline")}},
+		{"Tree", []key.Binding{k.Toggle, k.Collapse, k.ExpandAll, k.CollapseAll, k.Resource}},
+		{"Environments", []key.Binding{k.Summary}},
+		{"Triage (logs and results)", []key.Binding{as(k.Mark, "Mark done, follow-up, ignore or unmarked"), as(k.Note, "Edit Note"), k.TriageFilter, k.ExportTriage}},
+		{"Search and Config Inputs", []key.Binding{k.Submit, k.Cancel}},
+	}
+}
+
+// backKeys combines the back and quit bindings for the footer, both go back in every view but the first.
+func (k keyMap) backKeys() key.Binding {
+	keys := append(append([]string{}, k.Back.Keys()...), k.Quit.Keys()...)
+	return bind("Back", keys...)
+}
+
+// as returns a copy of b with a view-specific description for the footer.
+func as(b key.Binding, desc string) key.Binding {
+	b.SetHelp(b.Help().Key, desc)
+	return b
+}
+
+// renderBindings renders the footer entries of bindings, e.g. "f: Search | g: Group".
+func renderBindings(bindings ...key.Binding) string {
+	var parts []string
+	for _, b := range bindings {
+		if !b.Enabled() {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", keyStyle.Render(b.Help().Key), b.Help().Desc))
+	}
+	return strings.Join(parts, " | ")
+}
+
+// renderHelpOverlay lists every binding of the keymap, grouped like groups().
+func (m *model) renderHelpOverlay() {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Keys") + "\n")
+	for _, group := range m.keys.groups() {
+		b.WriteString("\n" + titleStyle.Render(group.title) + "\n")
+		for _, binding := range group.bindings {
+			b.WriteString(fmt.Sprintf("  %s %s\n", keyStyle.Render(fmt.Sprintf("%-16s", binding.Help().Key)), binding.Help().Desc))
+		}
+	}
+	note := fmt.Sprintf("Override keys with -%s action=\"key1 key2\",... or a %s map in the config file. Actions: %s",
+		argKeys, argKeys, strings.Join(sortedKeys(m.keys.byName()), ", "))
+	b.WriteString("\n" + helpStyle.Render(wordwrap.String(note, m.viewPort.Width)) + "\n")
+	m.viewPort.SetContent(b.String())
+	m.viewPort.GotoTop()
+}
+
+func (m model) updateHelpView(msg tea.Msg) (model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok && key.Matches(msg, m.keys.Help) {
+		m.goBack()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.viewPort, cmd = m.viewPort.Update(msg)
+	return m, cmd
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// commandMentions reports whether command passes address as one of its (possibly quoted) arguments.
+func commandMentions(command, address string) bool {
+	if address == "" {
+		return false
+	}
+	for _, field := range strings.Fields(command) {
+		if strings.Trim(field, `'"`) == address {
+			return true
+		}
+	}
+	return false
+}
+
+// logMatchesResult reports whether an execution log was run for a result: it ran the suggested
+// command, has the same terraform address, or names the resource in its command.
+func logMatchesResult(log CommandExecutionLog, item JSONResultItem) bool {
+	switch {
+	case item.Command != "" && strings.TrimSpace(log.Command) == strings.TrimSpace(item.Command):
+		return true
+	case log.TerraformAddress != "":
+		return log.TerraformAddress == item.Resource
+	default:
+		return commandMentions(log.Command, item.Resource)
+	}
+}
+
+// logsForResult returns the execution logs of the active report that belong to a result.
+func (m model) logsForResult(item JSONResultItem) []CommandExecutionLog {
+	var logs []CommandExecutionLog
+	for _, log := range m.report.ExecutionLogs {
+		if logMatchesResult(log, item) {
+			logs = append(logs, log)
+		}
+	}
+	return logs
+}
+
+// resultForLog finds the result category and item an execution log belongs to, searching the
+// categories in the order of resultCategories.
+func (m model) resultForLog(log CommandExecutionLog) (string, int, bool) {
+	for _, cat := range resultCategories {
+		for i, item := range m.report.Results.GetCategory(cat) {
+			if logMatchesResult(log, item) {
+				return cat, i, true
+			}
+		}
+	}
+	return "", 0, false
+}
+
+// loadLinkedLogs fills the list of execution logs that belong to item.
+func (m *model) loadLinkedLogs(item JSONResultItem) tea.Cmd {
+	logs := m.logsForResult(item)
+	items := make([]list.Item, len(logs))
+	for i, log := range logs {
+		items[i] = mainItem{log: log, mark: m.logMark(log)}
+	}
+	m.linkedLogList.Title = fmt.Sprintf("Execution Logs for %s (%d)", item.Resource, len(logs))
+	m.linkedLogList.SetItems(items)
+	m.linkedLogList.Select(0)
+	return nil
+}
+
+// showLinkedLogs jumps from a result to the execution logs that were run for it.
+func (m *model) showLinkedLogs() tea.Cmd {
+	item, ok := m.resultsResourceList.SelectedItem().(resultItem)
+	if !ok {
+		return nil
+	}
+	if len(m.logsForResult(item.JSONResultItem)) == 0 {
+		m.setNotification(fmt.Sprintf("No execution logs for %s", item.Resource), true)
+		return m.clearNotificationAfter(2 * time.Second)
+	}
+	m.state = m.pushView(viewLinkedLogs)
+	return m.loadLinkedLogs(item.JSONResultItem)
+}
+
+// showLinkedResult jumps from an execution log to the result category and item it belongs to.
+func (m *model) showLinkedResult() tea.Cmd {
+	cat, index, ok := m.resultForLog(m.activeExecutionLog)
+	if !ok {
+		m.setNotification("No result belongs to this execution log", true)
+		return m.clearNotificationAfter(2 * time.Second)
+	}
+	// The filters that would hide the result are lifted before any list is loaded.
+	item := m.report.Results.GetCategory(cat)[index]
+	if _, ok := knownFindings.suppression(cat, item); ok {
+		m.showSuppressed = true // the result is accepted by the baseline
+	}
+	if !matchesTriage(m.triage, m.resultMark(item)) {
+		m.triage = "" // the result is hidden by the triage filter
+		m.loadMainList()
+	}
+	m.loadResultsCategories()
+	for i, name := range resultCategories {
+		if name == cat {
+			m.resultsCategoryList.Select(i)
+		}
+	}
+	m.activeResultCategory = cat
+	m.loadResultsList()
+	m.selectResult(resultKey(item))
+	// The whole path is pushed, so going back walks through the item list and the categories.
+	m.pushView(viewResultsCategory)
+	m.pushView(viewResultsList)
+	m.state = m.pushView(viewResultsDetail)
+	m.renderResourceDetail(item)
+	return nil
+}
+
+func (m model) updateLinkedLogsView(msg tea.Msg) (model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.linkedLogList.FilterState() == list.Filtering {
+			break
+		}
+		switch {
+		case key.Matches(msg, m.keys.Open):
+			if i, ok := m.linkedLogList.SelectedItem().(mainItem); ok {
+				m.activeExecutionLog = i.log
+				m.state = m.pushView(viewExecutionLogDetail)
+				m.renderExecutionLogDetail()
+			}
+			return m, nil
+		case key.Matches(msg, m.keys.Mark, m.keys.Note):
+			m, cmd, _ := m.updateTriageKeys(msg)
+			return m, cmd
+		}
+	}
+	var cmd tea.Cmd
+	m.linkedLogList, cmd = m.linkedLogList.Update(msg)
+	return m, cmd
+}
@@ -4,12 +4,17 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 func main() {
 	var err error
+	// saved config edits apply before the flags read their environment defaults
+	if err = loadEnvFile(envFile()); err != nil {
+		log.Fatalf("Failed to load the saved config: %v", err)
+	}
 	check(configure(application()))
 
 	if *figs.Bool(argVersion) {
@@ -17,28 +22,155 @@ func main() {
 		os.Exit(0)
 	}
 
-	if reportData, err = loadReportData(*figs.String(argInputFile)); err != nil {
-		log.Fatalf("Failed to load report data: %v", err)
+	inputFile := *figs.String(argInputFile)
+	if secrets, err = newRedactor(*figs.Bool(argNoRedact), *figs.List(argRedact)); err != nil {
+		log.Fatalf("Invalid -%s: %v", argRedact, err)
+	}
+	if knownFindings, err = loadBaseline(*figs.String(argBaseline)); err != nil {
+		log.Fatalf("Failed to load the baseline: %v", err)
+	}
+	if plannedChanges, err = loadPlan(*figs.String(argPlan)); err != nil {
+		log.Fatalf("Failed to load the plan: %v", err)
+	}
+	if plannedChanges != nil {
+		// a plan is of one state, applied to the reports of other states it would flag false conflicts
+		if paths, err := resolveReportInputs(inputFile); err == nil && len(paths) > 1 {
+			log.Fatalf("-%s is the plan of one state, but -%s matches %d reports, pass the report the plan was made for", argPlan, argInputFile, len(paths))
+		}
+	}
+	if profiles, err = loadProfiles(*figs.String(argProfiles)); err != nil {
+		log.Fatalf("Failed to load the profiles: %v", err)
+	}
+	if name := *figs.String(argProfile); name != "" {
+		if _, ok := profiles[name]; !ok {
+			log.Fatalf("Unknown -%s %q, the profiles in %s are: %s", argProfile, name, *figs.String(argProfiles), orDash(strings.Join(sortedKeys(profiles), ", ")))
+		}
+	}
+	if *figs.Bool(argValidate) {
+		if err = validate(); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if *figs.String(argIngest) != "" || *figs.Bool(argTrend) {
+		if err = runHistory(); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if *figs.Bool(argGenerateBaseline) {
+		if err = printBaseline(); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if categories := *figs.List(argFailOn); len(categories) > 0 {
+		if err = checkFailOn(categories); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if *figs.Bool(argRefactor) {
+		if err = exportRefactor(); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if *figs.Bool(argReplay) {
+		if err = exportReplay(); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if format := *figs.String(argTickets); format != "" {
+		if err = exportTickets(format); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if format := *figs.String(argNotify); format != "" {
+		if err = sendNotification(format); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if path := *figs.String(argMetricsFile); path != "" {
+		if err = writeMetricsFile(path); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if addr := *figs.String(argMetrics); addr != "" {
+		if err = serveMetrics(addr); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if addr := *figs.String(argServe); addr != "" {
+		if err = serve(addr); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if *figs.Bool(argTriage) {
+		if err = exportTriage(); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
 	}
 
 	if *figs.Bool(argNonInteractive) {
 		fmt.Println("NON INTERACTIVE MODE ENABLED")
 		check(run())
 	} else {
-		errorCounts := aggregateErrors(reportData)
+		// Only the metadata is decoded up front, the execution logs stream in while the TUI is usable.
+		workspace, err := loadWorkspace(inputFile)
+		if err != nil {
+			log.Fatalf("Failed to load report data: %v", err)
+		}
+		m := initialModel(workspace, Version())
+		var warnings []string
+		for _, ws := range workspace {
+			if n := countIssues(ws.issues, issueWarning); n > 0 {
+				warnings = append(warnings, fmt.Sprintf("%d in %s", n, ws.path))
+			}
+		}
+		if len(warnings) > 0 {
+			m.setNotification(fmt.Sprintf("Schema warnings: %s, run with -%s for details", strings.Join(warnings, ", "), argValidate), true)
+		} else if expired := knownFindings.expiredEntries(); len(expired) > 0 {
+			m.setNotification(fmt.Sprintf("%d baseline entries have expired, their findings are shown again", len(expired)), true)
+		}
 		p := tea.NewProgram(
-			initialModel(reportData, errorCounts, Version()),
+			m,
 			tea.WithAltScreen(),
 			tea.WithMouseCellMotion(),
 		)
 		defer clearTUI()
-		var finalModel tea.Model
-		if finalModel, err = p.Run(); err != nil {
+		if _, err = p.Run(); err != nil {
 			log.Fatalf("Error running program: %v", err)
 		}
-
-		if m, ok := finalModel.(model); ok && m.quitMessage != "" {
-			fmt.Println(m.quitMessage)
-		}
 	}
 }
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestMain registers the flags once, figtree cannot define them twice in a process, with the
+// -save directory and the config file in a temporary directory.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "tfrr-test-")
+	if err != nil {
+		panic(err)
+	}
+	_ = os.Setenv(envConfigFile, filepath.Join(dir, "config.yaml"))
+	// the test flags first, figtree parses the command line again with the flags of the reader
+	flag.Parse()
+	args := os.Args
+	os.Args = []string{args[0], "-" + argSaveDir, filepath.Join(dir, "save")}
+	if err := configure(application()); err != nil {
+		panic(err)
+	}
+	os.Args = args
+	secrets = &redactor{}
+	code := m.Run()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// writeReport writes report to report.<env>.json in dir and returns its path.
+func writeReport(t *testing.T, dir, env string, report JSONOutput) string {
+	t.Helper()
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "report."+env+".json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// setFlag changes a string or bool flag for the test and restores it afterwards.
+func setFlag(t *testing.T, name string, value interface{}) {
+	t.Helper()
+	switch v := value.(type) {
+	case string:
+		previous := *figs.String(name)
+		figs.StoreString(name, v)
+		t.Cleanup(func() { figs.StoreString(name, previous) })
+	case bool:
+		previous := *figs.Bool(name)
+		figs.StoreBool(name, v)
+		t.Cleanup(func() { figs.StoreBool(name, previous) })
+	default:
+		t.Fatalf("setFlag: unsupported %T", value)
+	}
+}
+
+// captureStdout returns what run prints to stdout.
+func captureStdout(t *testing.T, run func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- data
+	}()
+	run()
+	os.Stdout = stdout
+	_ = w.Close()
+	return string(<-done)
+}
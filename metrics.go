@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// add sets a gauge sample. The labels are name, value pairs; the first sample of a name declares it.
+func (w *metricWriter) add(name, help string, value float64, labels ...string) {
+	name = metricsPrefix + name
+	if w.byName == nil {
+		w.byName = make(map[string]*metricFamily)
+	}
+	f, ok := w.byName[name]
+	if !ok {
+		f = &metricFamily{name: name, help: help}
+		w.byName[name] = f
+		w.families = append(w.families, f)
+	}
+	f.samples = append(f.samples, metricSample{labels: labels, value: value})
+}
+
+// String renders the gauges in the Prometheus text exposition format.
+func (w *metricWriter) String() string {
+	var b strings.Builder
+	for _, f := range w.families {
+		b.WriteString(fmt.Sprintf("# HELP %s %s\n# TYPE %s gauge\n", f.name, f.help, f.name))
+		for _, s := range f.samples {
+			b.WriteString(f.name)
+			if len(s.labels) > 0 {
+				b.WriteString("{")
+				for i := 0; i+1 < len(s.labels); i += 2 {
+					if i > 0 {
+						b.WriteString(",")
+					}
+					b.WriteString(fmt.Sprintf("%s=\"%s\"", s.labels[i], metricLabelValue(s.labels[i+1])))
+				}
+				b.WriteString("}")
+			}
+			b.WriteString(" " + strconv.FormatFloat(s.value, 'f', -1, 64) + "\n")
+		}
+	}
+	return b.String()
+}
+
+// metricLabelValue escapes a label value: backslashes, double quotes and newlines.
+func metricLabelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+// previousChecksum returns the state checksum of the latest run of the same state in the history
+// store before this report, "" when the store has none.
+func previousChecksum(h *historyStore, report *JSONOutput) string {
+	for i := len(h.Runs) - 1; i >= 0; i-- {
+		run := h.Runs[i]
+		if run.State != report.State || runKey(run.StateChecksum, run.StateVersion, run.Digest) == runKey(report.StateChecksum, report.StateVersion, "") {
+			continue
+		}
+		return run.StateChecksum
+	}
+	return ""
+}
+
+// collectMetrics reads the -input reports and renders their gauges. A report that cannot be read
+// only reports itself down, so one broken file does not hide the others.
+func collectMetrics(input string, now time.Time) (string, error) {
+	paths, err := resolveEnvironmentReports(input)
+	if err != nil {
+		return "", err
+	}
+	h, err := loadHistory(historyPath())
+	if err != nil {
+		return "", err
+	}
+	w := &metricWriter{}
+	for _, path := range paths {
+		env := reportEnvironment(path)
+		report, err := loadReportData(path)
+		if err != nil {
+			log.Printf("metrics: %s: %v", path, err)
+			w.add("report_up", "Whether the report could be read.", 0, "env", env)
+			continue
+		}
+		w.add("report_up", "Whether the report could be read.", 1, "env", env)
+
+		previous := previousChecksum(h, report)
+		changed := 0.0
+		if previous != "" && previous != report.StateChecksum {
+			changed = 1
+		}
+		// no checksum labels: every run would start a new series
+		w.add("report_info", "Report metadata, always 1.", 1,
+			"env", env, "state", report.State, "region", report.Region, "tf_version", report.TFVersion, "version", report.Version)
+		w.add("state_checksum_changed", "1 when the state checksum differs from the previous run of the state in the -history store.", changed, "env", env)
+		w.add("state_version", "Serial of the terraform state the report was run against.", float64(report.StateVersion), "env", env)
+
+		if info, err := os.Stat(path); err == nil {
+			w.add("report_timestamp_seconds", "Modification time of the report file.", float64(info.ModTime().Unix()), "env", env)
+			w.add("report_age_seconds", "Seconds since the report file was written.", now.Sub(info.ModTime()).Truncate(time.Second).Seconds(), "env", env)
+		}
+		appErr := 0.0
+		if report.ApplicationError != "" {
+			appErr = 1
+		}
+		w.add("application_error", "1 when the run ended with an application error.", appErr, "env", env)
+
+		for _, cat := range resultCategories {
+			results, suppressed := visibleResults(report, cat, false)
+			w.add("results", "Results per category, without the ones the -baseline suppresses.", float64(len(results)), "env", env, "category", cat)
+			w.add("results_suppressed", "Results per category that the -baseline suppresses.", float64(suppressed), "env", env, "category", cat)
+		}
+
+		failed := 0
+		for _, log := range report.ExecutionLogs {
+			if log.ExitCode != 0 {
+				failed++
+			}
+		}
+		w.add("execution_logs", "Commands executed during the run.", float64(len(report.ExecutionLogs)), "env", env)
+		w.add("execution_logs_failed", "Commands that exited non-zero.", float64(failed), "env", env)
+
+		counts := aggregateErrors(report)
+		for _, pattern := range commonTerraformErrors {
+			n := 0
+			if v, ok := counts.Load(pattern); ok {
+				n = v.(*ErrorCount).Count
+			}
+			w.add("error_pattern_logs", "Execution logs whose output contains a common terraform error.", float64(n), "env", env, "pattern", pattern)
+		}
+	}
+	return w.String(), nil
+}
+
+// serveMetrics serves /metrics for -metrics. The reports are read again on every scrape.
+func serveMetrics(addr string) error {
+	input := *figs.String(argInputFile)
+	if _, err := collectMetrics(input, time.Now()); err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		body, err := collectMetrics(input, time.Now())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = w.Write([]byte(body))
+	})
+	log.Printf("Serving metrics of %s on %s/metrics", input, addr)
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return srv.ListenAndServe()
+}
+
+// writeMetricsFile writes the gauges for the node_exporter textfile collector, for -metrics-file.
+// The file is replaced in one rename, so the collector never reads half of it.
+func writeMetricsFile(path string) error {
+	if filepath.Ext(path) != ".prom" {
+		return fmt.Errorf("-%s: %s does not end in .prom, the textfile collector would ignore it", argMetricsFile, path)
+	}
+	body, err := collectMetrics(*figs.String(argInputFile), time.Now())
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(body); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCollectMetrics(t *testing.T) {
+	dir := t.TempDir()
+	report := JSONOutput{State: "s3://bucket/prod.tfstate", StateChecksum: "abc123", Region: "us-east-1", TFVersion: "1.9.0"}
+	for i := 0; i < 200; i++ {
+		report.ExecutionLogs = append(report.ExecutionLogs, CommandExecutionLog{Command: "terraform import x", Stderr: commonTerraformErrors[0], ExitCode: 1})
+	}
+	writeReport(t, dir, "prod", report)
+
+	body, err := collectMetrics(dir, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`tf_reconcile_reader_report_up{env="prod"} 1`,
+		`tf_reconcile_reader_execution_logs_failed{env="prod"} 200`,
+		`tf_reconcile_reader_error_pattern_logs{env="prod",pattern="` + metricLabelValue(commonTerraformErrors[0]) + `"} 200`,
+		`tf_reconcile_reader_state_checksum_changed{env="prod"} 0`,
+	} {
+		if !strings.Contains(body, want+"\n") {
+			t.Errorf("metrics do not contain %s", want)
+		}
+	}
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "tf_reconcile_reader_report_info{") && strings.Contains(line, "checksum") {
+			t.Errorf("report_info has a checksum label: %s", line)
+		}
+	}
+}
@@ -6,11 +6,11 @@ import (
 	"os"
 	"os/exec"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/andreimerlescu/prettyboy/prettyboy"
 	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -20,7 +20,7 @@ import (
 	"github.com/muesli/reflow/wordwrap"
 )
 
-func initialModel(report *JSONOutput, errors *sync.Map, tfrrVersion string) model {
+func initialModel(workspace []*workspaceReport, tfrrVersion string) model {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
@@ -30,32 +30,48 @@ func initialModel(report *JSONOutput, errors *sync.Map, tfrrVersion string) mode
 	ti.CharLimit = 369
 	ti.Width = 72
 
+	keys, _ := loadKeyMap() // -keys was checked by its validator
 	m := model{
-		report:      report,
-		state:       viewMain,
-		viewStack:   []viewState{viewMain},
-		spinNer:     s,
-		errorCounts: errors,
-		textInput:   ti,
+		keys:      keys,
+		workspace: workspace,
+		state:     viewMain,
+		viewStack: []viewState{viewMain},
+		spinNer:   s,
+		textInput: ti,
+		profile:   *figs.String(argProfile),
 		versions: versions{
-			tf:   report.TFVersion,
-			bfsm: report.Version,
 			bsmr: tfrrVersion,
 		},
 	}
+	// A single report opens straight into its execution logs, several start at the environment picker.
+	if len(workspace) > 1 {
+		m.state = viewEnvironments
+		m.viewStack = []viewState{viewEnvironments}
+	}
+	m.openReport(workspace[0])
 	return m
 }
 
 // A command that copies text to the clipboard.
 func copyToClipboardCmd(text string) tea.Cmd {
 	return func() tea.Msg {
-		err := clipboard.WriteAll(text)
+		err := clipboard.WriteAll(secrets.redact(text))
 		return copyStatusMsg{err: err}
 	}
 }
 
 func (m model) Init() tea.Cmd {
-	return m.spinNer.Tick
+	cmds := []tea.Cmd{m.spinNer.Tick}
+	if m.active.loading {
+		cmds = append(cmds, waitForLogBatch(m.active.stream))
+	}
+	if m.notification != "" {
+		cmds = append(cmds, m.clearNotificationAfter(5*time.Second))
+	}
+	if *figs.Bool(argWatch) {
+		cmds = append(cmds, watchReports(*figs.Duration(argWatchInterval)))
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -72,121 +88,129 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		if !m.ready {
 			delegate := itemDelegate{}
-			m.mainList = list.New(nil, delegate, 0, 0)
-			m.backupList = list.New(nil, delegate, 0, 0)
-			m.resultsCategoryList = list.New(nil, delegate, 0, 0)
-			m.resultsResourceList = list.New(nil, delegate, 0, 0)
-			m.configList = list.New(nil, delegate, 0, 0)
+			newList := func() list.Model {
+				l := list.New(nil, delegate, 0, 0)
+				// q, esc and ? belong to the keymap, the lists must not quit or open their own help.
+				// SetItems enables the quit keys again unless they are disabled this way.
+				l.DisableQuitKeybindings()
+				l.KeyMap.ShowFullHelp.SetEnabled(false)
+				l.KeyMap.CloseFullHelp.SetEnabled(false)
+				return l
+			}
+			m.environmentList = newList()
+			m.mainList = newList()
+			m.logTreeList = newList()
+			m.linkedLogList = newList()
+			m.backupList = newList()
+			m.resultsCategoryList = newList()
+			m.resultsResourceList = newList()
+			m.configList = newList()
+			m.profileList = newList()
 			m.viewPort = viewport.New(m.termWidth-4, viewportHeight)
 			m.ready = true
+			m.loadEnvironmentList()
 			m.loadMainList()
 		}
 
+		m.environmentList.SetSize(m.termWidth, listHeight)
 		m.mainList.SetSize(m.termWidth, listHeight)
+		m.logTreeList.SetSize(m.termWidth, listHeight)
+		m.linkedLogList.SetSize(m.termWidth, listHeight)
 		m.backupList.SetSize(m.termWidth, listHeight)
 		m.resultsCategoryList.SetSize(m.termWidth, listHeight)
 		m.resultsResourceList.SetSize(m.termWidth, listHeight)
 		m.configList.SetSize(m.termWidth, listHeight)
+		m.profileList.SetSize(m.termWidth, listHeight)
 		m.viewPort.Width = m.termWidth - 4
 		m.viewPort.Height = viewportHeight
 		return m, nil
 
 	case tea.KeyMsg:
-		if m.mainList.FilterState() == list.Filtering {
+		if m.capturesInput() {
 			break
 		}
-		keyStr := msg.String()
+		// Navigation is the same in every view: back and quit pop the view stack and only quit
+		// from the first view, root returns to the first view.
 		switch {
-		case keyStr == "ctrl+c":
+		case key.Matches(msg, m.keys.ForceQuit):
 			return m, tea.Quit
-		case keyStr == "q":
-			if m.state == viewExecutionLogDetail {
-				m.state = viewMain
-				m.viewPort.GotoTop()
+		case key.Matches(msg, m.keys.Help) && m.state != viewHelp:
+			m.state = m.pushView(viewHelp)
+			m.renderHelpOverlay()
+			return m, nil
+		case key.Matches(msg, m.keys.Root) && len(m.viewStack) > 1:
+			m.goRoot()
+			return m, nil
+		case key.Matches(msg, m.keys.Back, m.keys.Quit) && !m.claimsBack(msg):
+			if len(m.viewStack) > 1 {
+				m.goBack()
 				return m, nil
 			}
-			return m, tea.Quit
+			if key.Matches(msg, m.keys.Quit) {
+				return m, tea.Quit
+			}
 		}
 
 		switch m.state {
 		case viewMain:
 			// Handle list wrapping before passing the message to the list component
-			if keyStr == "down" || (*figs.Bool(argVimEnabled) && keyStr == "j") {
-				if m.mainList.Index() == len(m.mainList.Items())-1 {
-					m.mainList.Select(0)
-					return m, nil
-				}
+			if key.Matches(msg, m.keys.Down) && m.mainList.Index() == len(m.mainList.Items())-1 {
+				m.mainList.Select(0)
+				return m, nil
 			}
-			if keyStr == "up" || (*figs.Bool(argVimEnabled) && keyStr == "k") {
-				if m.mainList.Index() == 0 {
-					m.mainList.Select(len(m.mainList.Items()) - 1)
-					return m, nil
-				}
+			if key.Matches(msg, m.keys.Up) && m.mainList.Index() == 0 {
+				m.mainList.Select(len(m.mainList.Items()) - 1)
+				return m, nil
 			}
-			if keyStr == "enter" || keyStr == "s" || keyStr == "l" {
-				if i, ok := m.mainList.SelectedItem().(mainItem); ok {
-					m.state = viewExecutionLogDetail
-
-					// --- Corrected Filtering and Wrapping Logic ---
-
-					// 1. Process stdout: filter [command], then truncate at ::debug::
-					stdoutCleaned := filterLinesWithPrefix(i.log.Stdout, "[command]")
-					stdoutFinal := truncateLogAtMarker(stdoutCleaned, "::debug::")
-
-					// 2. Process stderr: filter [command], then truncate at ::debug::
-					stderrCleaned := filterLinesWithPrefix(i.log.Stderr, "[command]")
-					stderrFinal := truncateLogAtMarker(stderrCleaned, "::debug::")
-
-					var content strings.Builder
-					// 3. Wrap the command itself
-					wrappedCommand := wordwrap.String(prettyboy.Command(i.log.Command), m.viewPort.Width)
-
-					content.WriteString(titleStyle.Render("COMMAND:") + "\n")
-					content.WriteString(wrappedCommand + "\n\n")
-					content.WriteString(titleStyle.Render("STDOUT:") + "\n")
-					content.WriteString(wordwrap.String(stdoutFinal, m.viewPort.Width) + "\n\n")
-					content.WriteString(titleStyle.Render("STDERR:") + "\n")
-					content.WriteString(wordwrap.String(stderrFinal, m.viewPort.Width) + "\n\n")
-					content.WriteString(titleStyle.Render("EXIT CODE:") + "\n")
-					content.WriteString(fmt.Sprintf("%d\n", i.log.ExitCode))
-					if i.log.Error != "" {
-						content.WriteString(titleStyle.Render("ERROR:") + "\n")
-						content.WriteString(i.log.Error + "\n")
-					}
-					m.viewPort.SetContent(content.String())
+			if key.Matches(msg, m.keys.Open, m.keys.Select) {
+				if i, ok := m.mainList.SelectedItem().(mainItem); ok && !i.placeholder {
+					m.activeExecutionLog = i.log
+					m.state = m.pushView(viewExecutionLogDetail)
+					m.renderExecutionLogDetail()
 				}
 				return m, nil
 			}
 		case viewExecutionLogDetail:
-			switch keyStr {
-			case "t", "n":
-				m.viewPort.GotoTop()
-				return m, nil
-			case "b":
-				m.viewPort.GotoBottom()
-				return m, nil
-			case "c":
-				if i, ok := m.mainList.SelectedItem().(mainItem); ok {
-					return m, copyToClipboardCmd(i.log.Command)
+			switch {
+			case key.Matches(msg, m.keys.NextMatch, m.keys.PrevMatch):
+				next := key.Matches(msg, m.keys.NextMatch)
+				if m.search.re != nil {
+					m.jumpToMatch(next)
+					return m, nil
 				}
-			case "h":
-				if *figs.Bool(argVimEnabled) {
-					m.state = viewMain
+				if next {
 					m.viewPort.GotoTop()
 					return m, nil
 				}
-				m.setNotification("wtf dude???", false)
-			case "esc":
-				m.state = viewMain
+			case key.Matches(msg, m.keys.Top):
 				m.viewPort.GotoTop()
 				return m, nil
+			case key.Matches(msg, m.keys.Bottom):
+				m.viewPort.GotoBottom()
+				return m, nil
+			case key.Matches(msg, m.keys.Copy):
+				return m, copyToClipboardCmd(m.activeExecutionLog.Command)
 			}
 		}
 
 	case spinner.TickMsg:
 		m.spinNer, cmd = m.spinNer.Update(msg)
+		if m.planRunning && m.state == viewPlanPreview {
+			m.renderPlanPreview()
+		}
 		return m, cmd
 
+	case planPreviewMsg:
+		// the outcome of an earlier preview is dropped once another result is previewed
+		if !m.planRunning || msg.address != m.planPreview.address {
+			return m, nil
+		}
+		m.planPreview, m.planRunning = msg, false
+		if m.state == viewPlanPreview {
+			m.renderPlanPreview()
+		}
+		return m, nil
+
 	case copyStatusMsg:
 		if msg.err != nil {
 			m.setNotification(fmt.Sprintf("Error copying: %v", msg.err), true)
@@ -199,9 +223,37 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.notification = ""
 		return m, nil
 
+	case watchTickMsg:
+		return m, tea.Batch(m.checkReports(), watchReports(*figs.Duration(argWatchInterval)))
+
+	case reportReloadedMsg:
+		return m, m.applyReload(msg)
+
+	case logBatchMsg:
+		from := len(msg.report.ExecutionLogs)
+		msg.report.ExecutionLogs = append(msg.report.ExecutionLogs, msg.logs...)
+		ws := m.workspaceFor(msg.report)
+		ws.read, ws.total = msg.read, msg.total
+		ws.loading = !msg.done
+		if m.ready && msg.report == m.report {
+			m.appendLogs(from)
+		}
+		if !msg.done {
+			return m, waitForLogBatch(msg.stream)
+		}
+		if msg.report == m.report {
+			m.errorCounts = aggregateErrors(msg.report)
+		}
+		if msg.err != nil {
+			m.setNotification(fmt.Sprintf("Stopped loading execution logs of %s: %v", ws.path, msg.err), true)
+			return m, m.clearNotificationAfter(5 * time.Second)
+		}
+		m.setNotification(fmt.Sprintf("Loaded %d execution logs from %s", len(msg.report.ExecutionLogs), ws.path), false)
+		return m, m.clearNotificationAfter(2 * time.Second)
+
 	case commandOutputMsg:
-		m.commandRunner.stdout = msg.stdout
-		m.commandRunner.stderr = msg.stderr
+		m.commandRunner.stdout = secrets.redact(msg.stdout)
+		m.commandRunner.stderr = secrets.redact(msg.stderr)
 		m.commandRunner.exitError = msg.err
 		// Append to in-memory logs
 		source := "LOCAL"
@@ -209,10 +261,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			source = "GITHUB ACTIONS"
 		}
 		newLog := CommandExecutionLog{
-			Command: m.commandRunner.cmd,
-			Stdout:  msg.stdout,
-			Stderr:  msg.stderr,
-			Source:  source,
+			TerraformAddress: m.commandRunner.address,
+			Command:          m.commandRunner.cmd,
+			Stdout:           msg.stdout,
+			Stderr:           msg.stderr,
+			Source:           source,
 		}
 		if msg.err != nil {
 			newLog.Error = msg.err.Error()
@@ -221,7 +274,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				newLog.ExitCode = exitErr.ExitCode()
 			}
 		}
+		newLog = secrets.redactLog(newLog)
 		m.report.ExecutionLogs = append(m.report.ExecutionLogs, newLog)
+		m.sessionRuns = append(m.sessionRuns, sessionRun{at: time.Now(), log: newLog})
 		m.loadMainList() // Refresh the main list
 		return m, nil
 
@@ -239,6 +294,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	// View-specific updates
 	switch m.state {
+	case viewEnvironments:
+		m, cmd = m.updateEnvironmentsView(msg)
+	case viewEnvironmentSummary:
+		m, cmd = m.updateEnvironmentSummaryView(msg)
 	case viewMain:
 		return m.updateMainView(msg)
 	case viewBackup:
@@ -259,6 +318,24 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m, cmd = m.updateConfigEditView(msg)
 	case viewCommandRunner:
 		m, cmd = m.updateCommandRunnerView(msg)
+	case viewSearch:
+		m, cmd = m.updateSearchView(msg)
+	case viewLogTree:
+		m, cmd = m.updateLogTreeView(msg)
+	case viewLinkedLogs:
+		m, cmd = m.updateLinkedLogsView(msg)
+	case viewResource:
+		m, cmd = m.updateResourceView(msg)
+	case viewHelp:
+		m, cmd = m.updateHelpView(msg)
+	case viewAnnotate:
+		m, cmd = m.updateAnnotateView(msg)
+	case viewTrend:
+		m, cmd = m.updateTrendView(msg)
+	case viewProfiles:
+		m, cmd = m.updateProfilesView(msg)
+	case viewPlanPreview:
+		m, cmd = m.updatePlanPreviewView(msg)
 	default:
 	}
 	cmds = append(cmds, cmd)
@@ -276,8 +353,14 @@ func (m model) View() string {
 
 	var mainContent string
 	switch m.state {
+	case viewEnvironments:
+		mainContent = m.environmentList.View()
 	case viewMain:
 		mainContent = m.mainList.View()
+	case viewLogTree:
+		mainContent = m.logTreeList.View()
+	case viewLinkedLogs:
+		mainContent = m.linkedLogList.View()
 	case viewBackup:
 		mainContent = m.backupList.View()
 	case viewResultsCategory:
@@ -286,17 +369,32 @@ func (m model) View() string {
 		mainContent = m.resultsResourceList.View()
 	case viewConfig:
 		mainContent = m.configList.View()
-	case viewBackupDetail, viewResultsDetail, viewExecutionLogDetail:
+	case viewProfiles:
+		mainContent = m.profileList.View()
+	case viewBackupDetail, viewResultsDetail, viewExecutionLogDetail, viewEnvironmentSummary, viewResource, viewHelp, viewTrend, viewPlanPreview:
 		mainContent = m.viewPort.View()
 	case viewConfigEdit:
 		mainContent = fmt.Sprintf(
 			"Editing %s:\n\n%s\n\n%s",
 			keyStyle.Render(m.activeConfigItem.key),
 			m.textInput.View(),
-			helpStyle.Render("(esc to cancel, enter to save and quit)"),
+			helpStyle.Render("(esc to cancel, enter to save)"),
 		)
 	case viewCommandRunner:
 		mainContent = m.viewCommandRunner()
+	case viewSearch:
+		mainContent = fmt.Sprintf(
+			"Search execution logs:\n\n%s\n\n%s",
+			m.textInput.View(),
+			helpStyle.Render("(Go regular expression, all lowercase matches case-insensitively, esc to cancel)"),
+		)
+	case viewAnnotate:
+		mainContent = fmt.Sprintf(
+			"Note on %s:\n\n%s\n\n%s",
+			keyStyle.Render(m.annotating),
+			m.textInput.View(),
+			helpStyle.Render("(esc to cancel, enter to save, an empty note removes it)"),
+		)
 	default:
 		mainContent = "Unknown view"
 	}
@@ -313,24 +411,74 @@ func (m model) View() string {
 func (m model) updateMainView(msg tea.Msg) (model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q":
-			return m, tea.Quit
-		case "b":
+		switch {
+		case key.Matches(msg, m.keys.Backups):
 			m.state = m.pushView(viewBackup)
 			return m, m.loadBackupList()
-		case "r":
+		case key.Matches(msg, m.keys.Results):
 			m.state = m.pushView(viewResultsCategory)
 			return m, m.loadResultsCategories()
-		case "c":
+		case key.Matches(msg, m.keys.Configs):
 			m.state = m.pushView(viewConfig)
 			return m, m.loadConfigList()
-		case "enter":
-			if i, ok := m.mainList.SelectedItem().(mainItem); ok {
-				m.activeExecutionLog = i.log
-				m.state = m.pushView(viewExecutionLogDetail)
-				m.renderExecutionLogDetail()
+		case key.Matches(msg, m.keys.Trend):
+			return m, m.showTrend()
+		case key.Matches(msg, m.keys.Profiles):
+			return m, m.showProfiles()
+		case key.Matches(msg, m.keys.ExportRefactor):
+			if m.active.loading {
+				m.setNotification("Wait until the execution logs are loaded.", true)
+				return m, m.clearNotificationAfter(2 * time.Second)
+			}
+			path, err := m.writeRefactor()
+			if err != nil {
+				m.setNotification(fmt.Sprintf("Failed to write %s: %v", path, err), true)
+				return m, m.clearNotificationAfter(3 * time.Second)
+			}
+			m.setNotification(fmt.Sprintf("Wrote the moved and removed blocks to %s", path), false)
+			return m, m.clearNotificationAfter(3 * time.Second)
+		case key.Matches(msg, m.keys.ExportReplay):
+			if m.active.loading {
+				m.setNotification("Wait until the execution logs are loaded.", true)
+				return m, m.clearNotificationAfter(2 * time.Second)
 			}
+			path, err := m.writeReplay()
+			if err != nil {
+				m.setNotification(fmt.Sprintf("Failed to write the replay script: %v", err), true)
+				return m, m.clearNotificationAfter(5 * time.Second)
+			}
+			m.setNotification(fmt.Sprintf("Wrote a replay script of the listed logs to %s", path), false)
+			return m, m.clearNotificationAfter(3 * time.Second)
+		case key.Matches(msg, m.keys.Group):
+			m.state = m.pushView(viewLogTree)
+			return m, m.loadLogTree()
+		case key.Matches(msg, m.keys.Sort):
+			m.logSort = (m.logSort + 1) % logSortKeys
+			m.loadMainList()
+			return m, nil
+		case key.Matches(msg, m.keys.FailedOnly):
+			m.facets.failedOnly = !m.facets.failedOnly
+			m.loadMainList()
+			return m, nil
+		case key.Matches(msg, m.keys.GitHubOnly):
+			m.facets.githubOnly = !m.facets.githubOnly
+			m.loadMainList()
+			return m, nil
+		case key.Matches(msg, m.keys.HasStderr):
+			m.facets.hasStderr = !m.facets.hasStderr
+			m.loadMainList()
+			return m, nil
+		case key.Matches(msg, m.keys.Mark, m.keys.Note, m.keys.TriageFilter, m.keys.ExportTriage):
+			m, cmd, _ := m.updateTriageKeys(msg)
+			return m, cmd
+		case key.Matches(msg, m.keys.Search):
+			m.textInput.SetValue(m.search.query)
+			m.textInput.Placeholder = "regex across command, stdout, stderr, error and address"
+			m.textInput.Focus()
+			m.state = m.pushView(viewSearch)
+			return m, nil
+		case key.Matches(msg, m.keys.Back) && m.search.re != nil && m.mainList.FilterState() == list.Unfiltered:
+			m.clearSearch()
 			return m, nil
 		}
 	}
@@ -342,7 +490,7 @@ func (m model) updateMainView(msg tea.Msg) (model, tea.Cmd) {
 func (m model) updateBackupView(msg tea.Msg) (model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		if msg.Type == tea.KeyEnter {
+		if key.Matches(msg, m.keys.Open) {
 			if i, ok := m.backupList.SelectedItem().(backupItem); ok {
 				m.activeBackupItem = i
 				m.state = m.pushView(viewBackupDetail)
@@ -359,10 +507,10 @@ func (m model) updateBackupView(msg tea.Msg) (model, tea.Cmd) {
 func (m model) updateBackupDetailView(msg tea.Msg) (model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "c":
+		switch {
+		case key.Matches(msg, m.keys.Copy):
 			return m, copyToClipboardCmd(m.activeBackupItem.val)
-		case "enter":
+		case key.Matches(msg, m.keys.Open):
 			path := m.activeBackupItem.val
 			if !isValidPath(path) {
 				m.setNotification(fmt.Sprintf("Local path not found: %s", path), true)
@@ -387,13 +535,16 @@ func (m model) updateBackupDetailView(msg tea.Msg) (model, tea.Cmd) {
 func (m model) updateResultsCategoryView(msg tea.Msg) (model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		if msg.Type == tea.KeyEnter {
+		if key.Matches(msg, m.keys.Open) {
 			if i, ok := m.resultsCategoryList.SelectedItem().(resultCategoryItem); ok {
 				m.activeResultCategory = i.name
 				m.state = m.pushView(viewResultsList)
 				return m, m.loadResultsList()
 			}
 		}
+		if key.Matches(msg, m.keys.ShowSuppressed) {
+			return m, m.toggleSuppressed()
+		}
 	}
 	var cmd tea.Cmd
 	m.resultsCategoryList, cmd = m.resultsCategoryList.Update(msg)
@@ -403,12 +554,21 @@ func (m model) updateResultsCategoryView(msg tea.Msg) (model, tea.Cmd) {
 func (m model) updateResultsListView(msg tea.Msg) (model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		if msg.Type == tea.KeyEnter {
-			if i, ok := m.resultsResourceList.SelectedItem().(JSONResultItem); ok {
+		if m.resultsResourceList.FilterState() == list.Filtering {
+			break
+		}
+		if key.Matches(msg, m.keys.Open) {
+			if i, ok := m.resultsResourceList.SelectedItem().(resultItem); ok {
 				m.state = m.pushView(viewResultsDetail)
-				m.renderResourceDetail(i)
+				m.renderResourceDetail(i.JSONResultItem)
 			}
 		}
+		if key.Matches(msg, m.keys.ShowSuppressed) {
+			return m, m.toggleSuppressed()
+		}
+		if m, cmd, ok := m.updateTriageKeys(msg); ok {
+			return m, cmd
+		}
 	}
 	var cmd tea.Cmd
 	m.resultsResourceList, cmd = m.resultsResourceList.Update(msg)
@@ -418,15 +578,33 @@ func (m model) updateResultsListView(msg tea.Msg) (model, tea.Cmd) {
 func (m model) updateResultsDetailView(msg tea.Msg) (model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "X":
-			if i, ok := m.resultsResourceList.SelectedItem().(JSONResultItem); ok && i.Command != "" {
+		switch {
+		case key.Matches(msg, m.keys.Execute):
+			if i, ok := m.resultsResourceList.SelectedItem().(resultItem); ok && i.Command != "" {
+				p, err := m.execProfile()
+				if err != nil {
+					m.setNotification(fmt.Sprintf("Refusing to run: %v", err), true)
+					return m, m.clearNotificationAfter(5 * time.Second)
+				}
 				m.commandRunner.cmd = i.Command
+				m.commandRunner.address = i.Resource
 				m.state = m.pushView(viewCommandRunner)
-				return m, execCommand(i.Command)
+				return m, execCommand(i.Command, p)
 			}
 			m.setNotification("No command to execute for this item.", true)
 			return m, m.clearNotificationAfter(2 * time.Second)
+		case key.Matches(msg, m.keys.Preview):
+			return m, m.showPlanPreview()
+		case key.Matches(msg, m.keys.Logs):
+			return m, m.showLinkedLogs()
+		case key.Matches(msg, m.keys.Resource):
+			if i, ok := m.resultsResourceList.SelectedItem().(resultItem); ok {
+				return m, m.showResource(i.Resource)
+			}
+			return m, nil
+		}
+		if m, cmd, ok := m.updateTriageKeys(msg); ok {
+			return m, cmd
 		}
 	}
 	var cmd tea.Cmd
@@ -437,12 +615,17 @@ func (m model) updateResultsDetailView(msg tea.Msg) (model, tea.Cmd) {
 func (m model) updateExecutionLogDetailView(msg tea.Msg) (model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "c":
-			return m, copyToClipboardCmd(m.activeExecutionLog.Command)
-		case "e":
+		switch {
+		case key.Matches(msg, m.keys.Edit):
 			// Suspend bubbletea to allow editor to take over terminal
 			return m, tea.Batch(tea.Suspend, editCommand(m.activeExecutionLog.Command))
+		case key.Matches(msg, m.keys.Result):
+			return m, m.showLinkedResult()
+		case key.Matches(msg, m.keys.Resource):
+			return m, m.showResource(m.activeExecutionLog.TerraformAddress)
+		}
+		if m, cmd, ok := m.updateTriageKeys(msg); ok {
+			return m, cmd
 		}
 	}
 	var cmd tea.Cmd
@@ -453,8 +636,12 @@ func (m model) updateExecutionLogDetailView(msg tea.Msg) (model, tea.Cmd) {
 func (m model) updateConfigView(msg tea.Msg) (model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		if msg.Type == tea.KeyEnter {
+		if key.Matches(msg, m.keys.Open) {
 			if i, ok := m.configList.SelectedItem().(configItem); ok {
+				if readBeforeEnvFile(i.key) {
+					m.setNotification(fmt.Sprintf("%s is read before the saved config loads, export it in the shell instead.", i.key), true)
+					return m, m.clearNotificationAfter(3 * time.Second)
+				}
 				m.activeConfigItem = i
 				m.textInput.SetValue(i.val)
 				m.textInput.Focus()
@@ -470,10 +657,12 @@ func (m model) updateConfigView(msg tea.Msg) (model, tea.Cmd) {
 func (m model) updateConfigEditView(msg tea.Msg) (model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "enter":
-			m.quitMessage = fmt.Sprintf("export %s=\"%s\"", m.activeConfigItem.key, m.textInput.Value())
-			return m, tea.Quit
+		switch {
+		case key.Matches(msg, m.keys.Submit):
+			return m, m.saveConfigEdit()
+		case key.Matches(msg, m.keys.Cancel):
+			m.state = m.popView()
+			return m, nil
 		}
 	}
 	var cmd tea.Cmd
@@ -484,8 +673,8 @@ func (m model) updateConfigEditView(msg tea.Msg) (model, tea.Cmd) {
 func (m model) updateCommandRunnerView(msg tea.Msg) (model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		if msg.Type == tea.KeyEnter {
-			m.state = m.popView() // Return to the results detail view
+		if key.Matches(msg, m.keys.Open) {
+			m.goBack() // Return to the results detail view
 		}
 	}
 	return m, nil
@@ -494,15 +683,80 @@ func (m model) updateCommandRunnerView(msg tea.Msg) (model, tea.Cmd) {
 // --- TUI Data Loading and Rendering ---
 
 func (m *model) loadMainList() {
-	items := make([]list.Item, len(m.report.ExecutionLogs))
-	if len(m.report.ExecutionLogs) == 0 {
-		items = []list.Item{mainItem{log: CommandExecutionLog{Command: "No execution logs found in this report."}}}
+	logs, matches := m.visibleLogs()
+	m.search.matches = matches
+	m.setMainItems(m.mainItems(logs))
+}
+
+// appendLogs adds the execution logs of the active report from index from on, a batch that was
+// just streamed, to the list and the log tree. Only the batch is filtered and sorted; with a sort
+// order it is merged into the logs already shown, which win ties since they come first in the
+// report.
+func (m *model) appendLogs(from int) {
+	logs, matches := m.filterLogs(from)
+	m.search.matches += matches
+	less := m.logLess()
+	sortLogs(logs, less)
+
+	items := m.mainList.Items()
+	if len(items) == 1 && items[0].(mainItem).placeholder {
+		items = nil
+	}
+	batch := m.mainItems(logs)
+	if less == nil {
+		items = append(items, batch...)
 	} else {
-		for i, log := range m.report.ExecutionLogs {
-			items[i] = mainItem{log: log}
+		merged := make([]list.Item, 0, len(items)+len(batch))
+		for len(items) > 0 && len(batch) > 0 {
+			if less(batch[0].(mainItem).log, items[0].(mainItem).log) {
+				merged, batch = append(merged, batch[0]), batch[1:]
+			} else {
+				merged, items = append(merged, items[0]), items[1:]
+			}
+		}
+		items = append(append(merged, items...), batch...)
+	}
+	m.setMainItems(items)
+
+	if m.logTree != nil {
+		m.logTree.add(logs, less)
+		m.refreshLogTree()
+	}
+}
+
+// mainItems wraps execution logs for the list.
+func (m model) mainItems(logs []CommandExecutionLog) []list.Item {
+	items := make([]list.Item, len(logs))
+	for i, log := range logs {
+		items[i] = mainItem{log: log, mark: m.logMark(log)}
+	}
+	return items
+}
+
+// setMainItems shows the items in the list with a title that counts them, or a placeholder that
+// says why there are none.
+func (m *model) setMainItems(items []list.Item) {
+	shown := len(items)
+	if len(items) == 0 {
+		placeholder := "No execution logs found in this report."
+		switch {
+		case m.active.loading:
+			placeholder = "Loading execution logs..."
+		case m.search.re != nil || m.logListStatus() != "":
+			placeholder = "No execution logs match the search, facets and triage filter."
 		}
+		items = []list.Item{mainItem{log: CommandExecutionLog{Command: placeholder}, placeholder: true}}
 	}
 	m.mainList.Title = fmt.Sprintf("Execution Logs (%d)", len(m.report.ExecutionLogs))
+	if shown != len(m.report.ExecutionLogs) {
+		m.mainList.Title = fmt.Sprintf("Execution Logs (%d of %d)", shown, len(m.report.ExecutionLogs))
+	}
+	if m.search.re != nil {
+		m.mainList.Title += fmt.Sprintf(" /%s/ %d matches", m.search.query, m.search.matches)
+	}
+	if m.active.loading {
+		m.mainList.Title += " loading..."
+	}
 	m.mainList.SetItems(items)
 }
 
@@ -516,23 +770,36 @@ func (m *model) loadBackupList() tea.Cmd {
 func (m *model) loadResultsCategories() tea.Cmd {
 	items := make([]list.Item, len(resultCategories))
 	for i, cat := range resultCategories {
-		items[i] = resultCategoryItem{
-			name:  cat,
-			count: len(m.report.Results.GetCategory(cat)),
+		results, suppressed := visibleResults(m.report, cat, m.showSuppressed)
+		item := resultCategoryItem{name: cat, count: len(results), conflicts: planConflicts(cat, results)}
+		if !m.showSuppressed {
+			item.suppressed = suppressed
 		}
+		items[i] = item
 	}
 	m.resultsCategoryList.Title = "Result Categories"
+	if m.showSuppressed {
+		m.resultsCategoryList.Title += " (including results accepted by the baseline)"
+	}
 	m.resultsCategoryList.SetItems(items)
 	return nil
 }
 
 func (m *model) loadResultsList() tea.Cmd {
-	results := m.report.Results.GetCategory(m.activeResultCategory)
-	items := make([]list.Item, len(results))
-	for i, res := range results {
-		items[i] = res
+	results := m.results(m.activeResultCategory)
+	var items []list.Item
+	for _, res := range results {
+		mark := m.resultMark(res)
+		if matchesTriage(m.triage, mark) {
+			_, suppressed := knownFindings.suppression(m.activeResultCategory, res)
+			planned, conflict := plannedResult(m.activeResultCategory, res)
+			items = append(items, resultItem{JSONResultItem: res, mark: mark, suppressed: suppressed, planned: planned, conflict: conflict})
+		}
 	}
 	m.resultsResourceList.Title = fmt.Sprintf("Results: %s (%d)", m.activeResultCategory, len(results))
+	if m.triage != "" {
+		m.resultsResourceList.Title = fmt.Sprintf("Results: %s (%d of %d) [triage: %s]", m.activeResultCategory, len(items), len(results), m.triage)
+	}
 	m.resultsResourceList.SetItems(items)
 	return nil
 }
@@ -551,8 +818,9 @@ func (m *model) loadConfigList() tea.Cmd {
 			envMap[parts[0]] = parts[1]
 		}
 	}
-	for key, val := range envMap {
-		items = append(items, configItem{key: key, val: val})
+	// sorted, so that the selection stays put when the list is reloaded after an edit
+	for _, key := range sortedKeys(envMap) {
+		items = append(items, configItem{key: key, val: envMap[key]})
 	}
 	m.configList.Title = "Environment Configuration"
 	m.configList.SetItems(items)
@@ -585,16 +853,39 @@ func (m *model) renderBackupDetail() {
 }
 
 func (m *model) renderExecutionLogDetail() {
-	var b strings.Builder
-	execLog := m.activeExecutionLog
-	b.WriteString(titleStyle.Render("Command:") + "\n")
-	b.WriteString(execLog.Command + "\n\n")
-	b.WriteString(titleStyle.Render(fmt.Sprintf("Exit Code: %d", execLog.ExitCode)) + "\n\n")
-	b.WriteString(titleStyle.Render("STDOUT:") + "\n")
-	b.WriteString(execLog.Stdout + "\n\n")
-	b.WriteString(titleStyle.Render("STDERR:") + "\n")
-	b.WriteString(errorStyle.Render(execLog.Stderr))
-	m.viewPort.SetContent(b.String())
+	execLog := displayedLog(m.activeExecutionLog)
+	width := m.viewPort.Width
+	m.search.hits = m.search.hits[:0]
+	m.search.current = -1
+
+	// Highlight search matches before wrapping, wordwrap keeps the escape sequences intact
+	var content strings.Builder
+	content.WriteString(titleStyle.Render("COMMAND:") + "\n")
+	m.writeSearchable(&content, execLog.Command, width)
+	content.WriteString("\n\n")
+	if execLog.TerraformAddress != "" {
+		content.WriteString(titleStyle.Render("ADDRESS:") + "\n")
+		m.writeSearchable(&content, execLog.TerraformAddress, 0)
+		content.WriteString("\n\n")
+	}
+	content.WriteString(titleStyle.Render("STDOUT:") + "\n")
+	m.writeSearchable(&content, execLog.Stdout, width)
+	content.WriteString("\n\n")
+	content.WriteString(titleStyle.Render("STDERR:") + "\n")
+	m.writeSearchable(&content, execLog.Stderr, width)
+	content.WriteString("\n\n")
+	content.WriteString(titleStyle.Render("EXIT CODE:") + "\n")
+	content.WriteString(fmt.Sprintf("%d\n", execLog.ExitCode))
+	if execLog.Error != "" {
+		content.WriteString(titleStyle.Render("ERROR:") + "\n")
+		m.writeSearchable(&content, execLog.Error, width)
+		content.WriteString("\n")
+	}
+	if mark := renderMark(m.logMark(m.activeExecutionLog)); mark != "" {
+		content.WriteString("\n" + mark)
+	}
+	m.viewPort.SetContent(content.String())
+	m.viewPort.GotoTop()
 }
 
 func (m *model) renderResourceDetail(item JSONResultItem) {
@@ -603,6 +894,9 @@ func (m *model) renderResourceDetail(item JSONResultItem) {
 	b.WriteString(fmt.Sprintf("%s %s\n", titleStyle.Render("Kind:"), item.Kind))
 	b.WriteString(fmt.Sprintf("%s %s\n", titleStyle.Render("Terraform ID:"), item.TFID))
 	b.WriteString(fmt.Sprintf("%s %s\n", titleStyle.Render("AWS ID:"), item.AWSID))
+	b.WriteString(renderMark(m.resultMark(item)))
+	b.WriteString(renderSuppression(m.activeResultCategory, item))
+	b.WriteString(renderPlannedAction(m.activeResultCategory, item))
 	b.WriteString(fmt.Sprintf("\n%s\n%s", titleStyle.Render("Message:"), item.Message))
 	if item.Command != "" {
 		b.WriteString(fmt.Sprintf("\n\n%s\n%s",
@@ -626,6 +920,29 @@ func (m *model) popView() viewState {
 	return m.viewStack[len(m.viewStack)-1]
 }
 
+// redrawView renders the detail view that is visible again after a pop. The detail views share
+// one viewport, so its content is whatever the view on top of them rendered last.
+func (m *model) redrawView() {
+	switch m.state {
+	case viewExecutionLogDetail:
+		m.renderExecutionLogDetail()
+	case viewResultsDetail:
+		if i, ok := m.resultsResourceList.SelectedItem().(resultItem); ok {
+			m.renderResourceDetail(i.JSONResultItem)
+		}
+	case viewResource:
+		m.renderResource()
+	case viewBackupDetail:
+		m.renderBackupDetail()
+	case viewEnvironmentSummary:
+		m.renderEnvironmentSummary()
+	case viewTrend:
+		m.renderTrendView()
+	case viewPlanPreview:
+		m.renderPlanPreview()
+	}
+}
+
 func (m *model) setNotification(msg string, isError bool) {
 	style := notificationStyle
 	if isError {
@@ -653,14 +970,37 @@ func (m model) viewCommandRunner() string {
 }
 
 func (m model) headerView() string {
-	title := fmt.Sprintf("%s: %s", appName, *figs.String(argInputFile))
+	title := fmt.Sprintf("%s: %s", appName, m.active.path)
+	if len(m.workspace) > 1 {
+		title = fmt.Sprintf("%s: [%s] %s", appName, m.active.env, m.active.path)
+	}
 	versions := fmt.Sprintf("tf v%s | rtfs %s | tfrr %s", m.versions.tf, m.versions.bfsm, m.versions.bsmr)
+	if m.active.loading {
+		title += " " + m.loadingProgress()
+	}
+	if *figs.Bool(argWatch) {
+		versions = "watching | " + versions
+	}
+	if m.profile != "" {
+		versions = "profile " + m.profile + " | " + versions
+	}
+	if status := m.logListStatus(); status != "" && (m.state == viewMain || m.state == viewLogTree) {
+		title += " [" + status + "]"
+	}
 	spaceWidth := m.termWidth - lipgloss.Width(title) - lipgloss.Width(versions)
 	if spaceWidth < 1 {
 		spaceWidth = 1
 	}
 	spacer := strings.Repeat(" ", spaceWidth)
-	return titleStyle.Render(title + spacer + versions)
+	return lipgloss.JoinVertical(lipgloss.Left, titleStyle.Render(title+spacer+versions), m.breadcrumbs())
+}
+
+// loadingProgress reports how far the background decoding of execution logs has come.
+func (m model) loadingProgress() string {
+	if m.active.total <= 0 {
+		return m.spinNer.View() + " loading logs"
+	}
+	return fmt.Sprintf("%s loading logs %d%%", m.spinNer.View(), m.active.read*100/m.active.total)
 }
 
 func (m model) footerView() string {
@@ -671,31 +1011,82 @@ func (m model) footerView() string {
 }
 
 func (m model) helpView() string {
-	k := func(s string) string { return keyStyle.Render(s) }
+	k := m.keys
+	navigate, scroll := "↑/↓: Navigate | ", "↑/↓: Scroll | "
+	var prefix string
+	var bindings []key.Binding
 	switch m.state {
+	case viewEnvironments:
+		prefix, bindings = navigate, []key.Binding{k.Open, k.Summary}
+	case viewEnvironmentSummary, viewHelp:
+		prefix = scroll
+	case viewTrend:
+		prefix, bindings = scroll, []key.Binding{k.Top, k.Bottom}
 	case viewMain:
-		return fmt.Sprintf("↑/↓: Navigate | Enter: Details | %s: Backups | %s: Results | %s: Configs | %s: Quit", k("b"), k("r"), k("c"), k("q"))
+		prefix, bindings = navigate, []key.Binding{as(k.Open, "Details"), k.Search, k.Group, k.Sort, k.FailedOnly, k.GitHubOnly, k.HasStderr, k.Mark, k.Note, k.TriageFilter, k.Backups, k.Results, k.Configs, k.Trend, k.Profiles}
+	case viewResultsList:
+		prefix, bindings = navigate, []key.Binding{as(k.Open, "Details"), k.Mark, k.Note, k.TriageFilter, k.ExportTriage}
+		if knownFindings != nil {
+			bindings = append(bindings, k.ShowSuppressed)
+		}
+	case viewLinkedLogs:
+		prefix, bindings = navigate, []key.Binding{as(k.Open, "Details"), k.Mark, k.Note}
 	case viewBackup:
-		return fmt.Sprintf("↑/↓: Navigate | Enter: Details | %s/%s: Back", k("q"), k("esc"))
+		prefix, bindings = navigate, []key.Binding{as(k.Open, "Details")}
 	case viewBackupDetail:
-		return fmt.Sprintf("↑/↓: Scroll | %s: Copy Path | Enter: Stat File | %s/%s: Back", k("c"), k("q"), k("esc"))
+		prefix, bindings = scroll, []key.Binding{as(k.Copy, "Copy Path"), as(k.Open, "Stat File")}
 	case viewResultsCategory:
-		return fmt.Sprintf("↑/↓: Navigate | Enter: View Items | %s/%s: Back", k("q"), k("esc"))
-	case viewResultsList:
-		return fmt.Sprintf("↑/↓: Navigate | Enter: Details | %s/%s: Back", k("q"), k("esc"))
+		prefix, bindings = navigate, []key.Binding{as(k.Open, "View Items")}
+		if knownFindings != nil {
+			bindings = append(bindings, k.ShowSuppressed)
+		}
 	case viewResultsDetail:
-		return fmt.Sprintf("↑/↓: Scroll | %s: Execute Command | %s/%s: Back", k("X"), k("q"), k("esc"))
+		prefix, bindings = scroll, []key.Binding{k.Execute}
+		if i, ok := m.resultsResourceList.SelectedItem().(resultItem); ok {
+			if _, _, ok := importTarget(m.activeResultCategory, i.JSONResultItem); ok {
+				bindings = append(bindings, k.Preview)
+			}
+		}
+		bindings = append(bindings, k.Logs, k.Resource, k.Mark, k.Note)
+	case viewPlanPreview:
+		prefix, bindings = scroll, []key.Binding{k.Top, k.Bottom, as(k.Copy, "Copy Generated HCL")}
 	case viewExecutionLogDetail:
-		return fmt.Sprintf("↑/↓: Scroll | %s: Copy Command | %s: Edit & Copy Command | %s/%s: Back", k("c"), k("e"), k("q"), k("esc"))
+		prefix = scroll
+		if m.search.re != nil {
+			bindings = append(bindings, as(k.NextMatch, fmt.Sprintf("Next Match (%s)", m.matchPosition())), k.PrevMatch)
+		}
+		bindings = append(bindings, as(k.Copy, "Copy Command"), k.Edit, k.Result, k.Resource, k.Mark, k.Note)
+	case viewSearch:
+		return renderBindings(as(k.Submit, "Search"), k.Cancel)
+	case viewResource:
+		prefix, bindings = scroll, []key.Binding{as(k.Copy, "Copy Address")}
+	case viewLogTree:
+		prefix, bindings = navigate, []key.Binding{as(k.Open, "Expand/Details"), k.Toggle, k.Collapse, k.ExpandAll, k.CollapseAll, k.Resource}
 	case viewConfig:
-		return fmt.Sprintf("↑/↓: Navigate | Enter: Edit | %s/%s: Back", k("q"), k("esc"))
+		prefix, bindings = navigate, []key.Binding{as(k.Open, "Edit")}
 	case viewConfigEdit:
-		return fmt.Sprintf("Enter: Save and Quit | %s: Back", k("esc"))
-	case viewCommandRunner:
-		return "Enter: Back"
+		return renderBindings(as(k.Submit, "Save"), k.Cancel)
+	case viewProfiles:
+		prefix, bindings = navigate, []key.Binding{as(k.Open, "Use Profile")}
+	case viewAnnotate:
+		return renderBindings(as(k.Submit, "Save Note"), k.Cancel)
+	}
+
+	switch depth := len(m.viewStack); {
+	case depth == 1:
+		bindings = append(bindings, k.Quit)
+	case m.state == viewMain && depth == 2 && len(m.workspace) > 1:
+		bindings = append(bindings, as(k.backKeys(), "Environments"))
 	default:
-		return fmt.Sprintf("Use %s or %s to go back. %s to quit.", k("q"), k("esc"), k("ctrl+c"))
+		bindings = append(bindings, k.backKeys())
+		if depth > 2 {
+			bindings = append(bindings, k.Root)
+		}
+	}
+	if m.state != viewHelp {
+		bindings = append(bindings, k.Help)
 	}
+	return prefix + renderBindings(bindings...)
 }
 
 // filterLinesWithPrefix removes lines that start with a given prefix.
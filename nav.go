@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// crumb names a view in the breadcrumbs, with the item it shows where that helps.
+func (m model) crumb(v viewState) string {
+	switch v {
+	case viewEnvironments:
+		return "Environments"
+	case viewEnvironmentSummary:
+		return "Summary"
+	case viewMain:
+		if len(m.workspace) > 1 {
+			return m.active.env
+		}
+		return "Execution Logs"
+	case viewLogTree:
+		return "Tree"
+	case viewExecutionLogDetail:
+		return "Log"
+	case viewLinkedLogs:
+		return "Linked Logs"
+	case viewBackup:
+		return "Backups"
+	case viewBackupDetail:
+		return m.activeBackupItem.key
+	case viewResultsCategory:
+		return "Results"
+	case viewResultsList:
+		return m.activeResultCategory
+	case viewResultsDetail:
+		if i, ok := m.resultsResourceList.SelectedItem().(resultItem); ok {
+			return i.Resource
+		}
+		return "Result"
+	case viewResource:
+		return "Resource " + m.activeResource
+	case viewConfig:
+		return "Configs"
+	case viewConfigEdit:
+		return "Edit " + m.activeConfigItem.key
+	case viewCommandRunner:
+		return "Run"
+	case viewSearch:
+		return "Search"
+	case viewHelp:
+		return "Help"
+	case viewAnnotate:
+		return "Note"
+	case viewTrend:
+		return "Trend"
+	case viewProfiles:
+		return "Profiles"
+	case viewPlanPreview:
+		return "Preview"
+	default:
+		return fmt.Sprintf("View %d", v)
+	}
+}
+
+// breadcrumbs renders the view stack, e.g. "prod › Results › ERROR › aws_s3_bucket.b", dropping
+// the oldest crumbs when it does not fit.
+func (m model) breadcrumbs() string {
+	crumbs := make([]string, len(m.viewStack))
+	for i, v := range m.viewStack {
+		crumbs[i] = m.crumb(v)
+	}
+	trail := strings.Join(crumbs, " › ")
+	for len(crumbs) > 1 && m.termWidth > 0 && lipgloss.Width(trail)+4 > m.termWidth {
+		crumbs = crumbs[1:]
+		trail = "… › " + strings.Join(crumbs, " › ")
+	}
+	return helpStyle.Render("  " + trail)
+}
+
+// claimsBack reports whether the current view uses the back key itself: to clear a list filter,
+// to clear the search, or (in vim mode, where h also goes back) to collapse a tree node.
+func (m model) claimsBack(msg tea.KeyMsg) bool {
+	if l := m.currentList(); l != nil && l.FilterState() == list.FilterApplied && key.Matches(msg, l.KeyMap.ClearFilter) {
+		return true
+	}
+	switch m.state {
+	case viewMain:
+		return m.search.re != nil && key.Matches(msg, m.keys.Back)
+	case viewLogTree:
+		return key.Matches(msg, m.keys.Collapse)
+	}
+	return false
+}
+
+// currentList returns the list the current view shows, nil for views without one.
+func (m *model) currentList() *list.Model {
+	switch m.state {
+	case viewMain:
+		return &m.mainList
+	case viewEnvironments:
+		return &m.environmentList
+	case viewLogTree:
+		return &m.logTreeList
+	case viewLinkedLogs:
+		return &m.linkedLogList
+	case viewBackup:
+		return &m.backupList
+	case viewResultsCategory:
+		return &m.resultsCategoryList
+	case viewResultsList:
+		return &m.resultsResourceList
+	case viewConfig:
+		return &m.configList
+	case viewProfiles:
+		return &m.profileList
+	}
+	return nil
+}
+
+// goBack returns to the previous view and redraws it.
+func (m *model) goBack() {
+	m.state = m.popView()
+	m.restoreView()
+}
+
+// goRoot returns to the first view, the execution logs or the environment picker.
+func (m *model) goRoot() {
+	m.viewStack = m.viewStack[:1]
+	m.state = m.viewStack[0]
+	m.restoreView()
+}
+
+// restoreView brings the view that is visible again after going back up to date.
+func (m *model) restoreView() {
+	switch m.state {
+	case viewEnvironments:
+		m.loadEnvironmentList()
+	default:
+		m.redrawView()
+	}
+}
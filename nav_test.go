@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// navReport is a report with a result in every category but INFO and a few execution logs.
+func navReport(env string) JSONOutput {
+	item := func(resource string) []JSONResultItem {
+		return []JSONResultItem{{Resource: resource, Kind: "aws_s3_bucket", Message: "drift"}}
+	}
+	report := JSONOutput{
+		State:  "s3://bucket/" + env + "/terraform.tfstate",
+		Region: "us-east-1",
+		Results: JSONResults{
+			OkResults:              item("aws_s3_bucket.ok"),
+			PotentialImportResults: item("aws_s3_bucket.import"),
+			RegionMismatchResults:  item("aws_s3_bucket.region"),
+			WarningResults:         item("aws_s3_bucket.warning"),
+			ErrorResults:           item("aws_s3_bucket.error"),
+			DangerousResults:       item("aws_s3_bucket.dangerous"),
+		},
+		Version: "v1.3.0",
+	}
+	for i := 0; i < 5; i++ {
+		report.ExecutionLogs = append(report.ExecutionLogs, CommandExecutionLog{
+			TerraformAddress: fmt.Sprintf("aws_s3_bucket.b%d", i),
+			Command:          fmt.Sprintf("terraform import 'aws_s3_bucket.b%d' bucket-%d", i, i),
+			Stdout:           "ok",
+		})
+	}
+	return report
+}
+
+// newNavModel opens the reports of envs the way main does and sizes the terminal, which creates
+// the lists, then waits until the execution logs of the first report are loaded.
+func newNavModel(t *testing.T, envs ...string) model {
+	t.Helper()
+	dir := t.TempDir()
+	for _, env := range envs {
+		writeReport(t, dir, env, navReport(env))
+	}
+	workspace, err := loadWorkspace(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := initialModel(workspace, "test")
+	m = update(t, m, tea.WindowSizeMsg{Width: 160, Height: 40})
+	return drainLogs(t, m)
+}
+
+// drainLogs feeds the execution log batches of the active report to the model.
+func drainLogs(t *testing.T, m model) model {
+	t.Helper()
+	for m.active.loading {
+		msg := waitForLogBatch(m.active.stream)()
+		if msg == nil {
+			t.Fatal("log stream closed before its last batch")
+		}
+		m = update(t, m, msg)
+	}
+	return m
+}
+
+func update(t *testing.T, m model, msg tea.Msg) model {
+	t.Helper()
+	next, _ := m.Update(msg)
+	updated, ok := next.(model)
+	if !ok {
+		t.Fatalf("Update returned %T", next)
+	}
+	return updated
+}
+
+// keyMsg builds the message of a key as bubbletea reports it, e.g. "enter", "down" or "r".
+func keyMsg(k string) tea.KeyMsg {
+	switch k {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "up":
+		return tea.KeyMsg{Type: tea.KeyUp}
+	case "home":
+		return tea.KeyMsg{Type: tea.KeyHome}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
+
+func press(t *testing.T, m model, keys ...string) model {
+	t.Helper()
+	for _, k := range keys {
+		m = update(t, m, keyMsg(k))
+	}
+	return m
+}
+
+// assertStack checks the view stack and that the breadcrumbs show it.
+func assertStack(t *testing.T, m model, crumbs ...string) {
+	t.Helper()
+	if len(m.viewStack) != len(crumbs) {
+		t.Fatalf("view stack depth = %d, want %d (%v)", len(m.viewStack), len(crumbs), m.viewStack)
+	}
+	if m.state != m.viewStack[len(m.viewStack)-1] {
+		t.Fatalf("state %v is not the top of the view stack %v", m.state, m.viewStack)
+	}
+	if trail := strings.Join(crumbs, " › "); !strings.Contains(m.breadcrumbs(), trail) {
+		t.Fatalf("breadcrumbs = %q, want %q", m.breadcrumbs(), trail)
+	}
+}
+
+func TestNavigationBackRestoresSelection(t *testing.T) {
+	m := newNavModel(t, "dev")
+	assertStack(t, m, "Execution Logs")
+
+	m = press(t, m, "down", "down", "enter")
+	assertStack(t, m, "Execution Logs", "Log")
+	m = press(t, m, "esc")
+	assertStack(t, m, "Execution Logs")
+	if got := m.mainList.Index(); got != 2 {
+		t.Fatalf("execution log selection after back = %d, want 2", got)
+	}
+
+	m = press(t, m, "r")
+	assertStack(t, m, "Execution Logs", "Results")
+	m = press(t, m, "down", "down", "down", "down", "down", "enter")
+	assertStack(t, m, "Execution Logs", "Results", "ERROR")
+	m = press(t, m, "enter")
+	assertStack(t, m, "Execution Logs", "Results", "ERROR", "aws_s3_bucket.error")
+
+	m = press(t, m, "esc", "esc")
+	assertStack(t, m, "Execution Logs", "Results")
+	if got := m.resultsCategoryList.Index(); got != 5 {
+		t.Fatalf("result category selection after back = %d, want 5", got)
+	}
+	// going forward again opens the category that was selected
+	m = press(t, m, "enter")
+	assertStack(t, m, "Execution Logs", "Results", "ERROR")
+}
+
+func TestNavigationRoot(t *testing.T) {
+	m := newNavModel(t, "dev")
+	m = press(t, m, "r", "down", "enter", "enter")
+	if len(m.viewStack) != 4 {
+		t.Fatalf("view stack depth = %d, want 4 (%v)", len(m.viewStack), m.viewStack)
+	}
+	m = press(t, m, "~")
+	assertStack(t, m, "Execution Logs")
+	if m.state != viewMain {
+		t.Fatalf("state after root = %v, want %v", m.state, viewMain)
+	}
+
+	m = press(t, m, "c", "home")
+	assertStack(t, m, "Execution Logs")
+}
+
+func TestNavigationQuitOnlyFromFirstView(t *testing.T) {
+	m := newNavModel(t, "dev")
+	m = press(t, m, "r", "?")
+	assertStack(t, m, "Execution Logs", "Results", "Help")
+
+	next, cmd := m.Update(keyMsg("q"))
+	m = next.(model)
+	if cmd != nil {
+		t.Fatalf("q in a pushed view returned a command, want it to go back")
+	}
+	assertStack(t, m, "Execution Logs", "Results")
+
+	m = press(t, m, "q")
+	assertStack(t, m, "Execution Logs")
+	if _, cmd = m.Update(keyMsg("esc")); cmd != nil {
+		if _, ok := cmd().(tea.QuitMsg); ok {
+			t.Fatal("esc in the first view quit")
+		}
+	}
+	if _, cmd = m.Update(keyMsg("q")); cmd == nil {
+		t.Fatal("q in the first view did not quit")
+	} else if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Fatal("q in the first view did not quit")
+	}
+}
+
+func TestNavigationEnvironments(t *testing.T) {
+	m := newNavModel(t, "dev", "prod")
+	assertStack(t, m, "Environments")
+
+	m = press(t, m, "down", "enter")
+	m = drainLogs(t, m)
+	assertStack(t, m, "Environments", "prod")
+	m = press(t, m, "r", "enter")
+	assertStack(t, m, "Environments", "prod", "Results", "INFO")
+
+	m = press(t, m, "~")
+	assertStack(t, m, "Environments")
+	if got := m.environmentList.Index(); got != 1 {
+		t.Fatalf("environment selection after root = %d, want 1", got)
+	}
+	m = press(t, m, "up", "enter")
+	assertStack(t, m, "Environments", "dev")
+	if m.active.env != "dev" {
+		t.Fatalf("active environment = %q, want dev", m.active.env)
+	}
+}
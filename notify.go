@@ -0,0 +1,276 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// buildNotifySummary condenses the -input reports into what a notification says: the counts per
+// category, the DANGEROUS results that are new since the -notify-baseline report of the same
+// environment (or since the baseline report, when both are a single report, e.g. of renamed
+// files) and the failed commands.
+func buildNotifySummary(input, baselineInput string) (notifySummary, error) {
+	summary := notifySummary{App: appName, Generated: time.Now().UTC(), Categories: resultCategories}
+	paths, err := resolveEnvironmentReports(input)
+	if err != nil {
+		return summary, err
+	}
+	previous := make(map[string]*JSONOutput)
+	var onlyPrevious *JSONOutput
+	if baselineInput != "" {
+		baselinePaths, err := resolveEnvironmentReports(baselineInput)
+		if err != nil {
+			return summary, fmt.Errorf("-%s: %w", argNotifyBaseline, err)
+		}
+		for _, path := range baselinePaths {
+			report, err := loadReportData(path)
+			if err != nil {
+				return summary, fmt.Errorf("%s: %w", path, err)
+			}
+			previous[reportEnvironment(path)] = report
+			onlyPrevious = report
+		}
+		// of several reports, one baseline report would stand in for environments it is not of
+		if len(baselinePaths) > 1 || len(paths) > 1 {
+			onlyPrevious = nil
+		}
+		summary.Compared = true
+	}
+
+	for _, path := range paths {
+		report, err := loadReportData(path)
+		if err != nil {
+			return summary, fmt.Errorf("%s: %w", path, err)
+		}
+		env := reportEnvironment(path)
+		r := notifyReport{
+			Env:    env,
+			Path:   path,
+			State:  report.State,
+			Region: report.Region,
+			Counts: make(map[string]int),
+			Logs:   len(report.ExecutionLogs),
+			Failed: []notifyFailure{},
+		}
+		for _, cat := range resultCategories {
+			results, suppressed := visibleResults(report, cat, false)
+			r.Counts[cat] = len(results)
+			r.Suppressed += suppressed
+		}
+
+		dangerous, _ := visibleResults(report, "DANGEROUS", false)
+		if dangerous == nil {
+			dangerous = []JSONResultItem{}
+		}
+		if summary.Compared {
+			before := previous[env]
+			if before == nil {
+				before = onlyPrevious
+			}
+			known := make(map[string]bool)
+			if before != nil {
+				for _, item := range before.Results.GetCategory("DANGEROUS") {
+					known[resultKey(item)] = true
+				}
+			}
+			r.Compared = before != nil
+			fresh := []JSONResultItem{}
+			for _, item := range dangerous {
+				if !known[resultKey(item)] {
+					fresh = append(fresh, item)
+				}
+			}
+			dangerous = fresh
+		}
+		r.DangerousTotal = len(dangerous)
+		r.Dangerous = dangerous[:min(len(dangerous), notifyMaxItems)]
+
+		failed := make(map[string]*notifyFailure)
+		for _, log := range report.ExecutionLogs {
+			if log.ExitCode == 0 {
+				continue
+			}
+			r.FailedLogs++
+			f, ok := failed[log.Command]
+			if !ok {
+				f = &notifyFailure{Command: log.Command, Address: log.TerraformAddress, ExitCode: log.ExitCode}
+				failed[log.Command] = f
+			}
+			f.Count++
+		}
+		for _, f := range failed {
+			r.Failed = append(r.Failed, *f)
+		}
+		sort.Slice(r.Failed, func(i, j int) bool {
+			if r.Failed[i].Count != r.Failed[j].Count {
+				return r.Failed[i].Count > r.Failed[j].Count
+			}
+			return r.Failed[i].Command < r.Failed[j].Command
+		})
+		r.FailedTotal = len(r.Failed)
+		r.Failed = r.Failed[:min(len(r.Failed), notifyMaxItems)]
+		summary.Reports = append(summary.Reports, r)
+	}
+	summary.Text = renderNotifyText(summary)
+	return summary, nil
+}
+
+// renderNotifyText writes the summary as the Markdown that Slack and Teams both render: backticks
+// for code and bullets, no tables.
+func renderNotifyText(s notifySummary) string {
+	var b strings.Builder
+	for i, r := range s.Reports {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(fmt.Sprintf("%s (`%s`, %s)\n", r.Env, orDash(r.State), orDash(r.Region)))
+		var counts []string
+		for _, cat := range resultCategories {
+			if r.Counts[cat] > 0 {
+				counts = append(counts, fmt.Sprintf("%s %d", cat, r.Counts[cat]))
+			}
+		}
+		if len(counts) == 0 {
+			counts = append(counts, "no results")
+		}
+		line := strings.Join(counts, ", ")
+		if r.Suppressed > 0 {
+			line += fmt.Sprintf(" (%d accepted by the baseline)", r.Suppressed)
+		}
+		b.WriteString(line + "\n")
+		b.WriteString(fmt.Sprintf("%d of %d commands failed\n", r.FailedLogs, r.Logs))
+
+		switch {
+		case s.Compared && !r.Compared:
+			b.WriteString("No baseline report to compare DANGEROUS results with.\n")
+		case s.Compared && r.DangerousTotal == 0:
+			b.WriteString("No new DANGEROUS results.\n")
+		case s.Compared:
+			b.WriteString(fmt.Sprintf("New DANGEROUS results (%d):\n", r.DangerousTotal))
+		case r.DangerousTotal > 0:
+			b.WriteString(fmt.Sprintf("DANGEROUS results (%d):\n", r.DangerousTotal))
+		}
+		for _, item := range r.Dangerous {
+			b.WriteString(fmt.Sprintf("• `%s` (%s) %s\n", item.Resource, orDash(item.Kind), item.Message))
+		}
+		if more := r.DangerousTotal - len(r.Dangerous); more > 0 {
+			b.WriteString(fmt.Sprintf("• and %d more\n", more))
+		}
+		if r.FailedTotal > 0 {
+			b.WriteString("Failed commands:\n")
+		}
+		for _, f := range r.Failed {
+			b.WriteString(fmt.Sprintf("• `%s` exit %d", f.Command, f.ExitCode))
+			if f.Count > 1 {
+				b.WriteString(fmt.Sprintf(" ×%d", f.Count))
+			}
+			b.WriteString("\n")
+		}
+		if more := r.FailedTotal - len(r.Failed); more > 0 {
+			b.WriteString(fmt.Sprintf("• and %d more\n", more))
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// renderNotifyPayload executes the -notify-template, or the built-in template of the format, and
+// checks that the result is JSON. Templates get the summary and a json function that encodes any
+// value, so strings never need escaping by hand.
+func renderNotifyPayload(format, templatePath string, summary notifySummary) ([]byte, error) {
+	name, text := format, notifyTemplates[format]
+	if templatePath != "" {
+		data, err := os.ReadFile(templatePath)
+		if err != nil {
+			return nil, err
+		}
+		name, text = templatePath, string(data)
+	}
+	tmpl, err := template.New(name).Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, summary); err != nil {
+		return nil, err
+	}
+	if !json.Valid(out.Bytes()) {
+		return nil, fmt.Errorf("%s did not render valid JSON:\n%s", name, out.String())
+	}
+	return out.Bytes(), nil
+}
+
+// postNotification sends the payload to the webhook. Chat webhooks answer 2xx on success.
+func postNotification(webhook string, payload []byte) error {
+	client := &http.Client{Timeout: notifyTimeout}
+	res, err := client.Post(webhook, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err // without the webhook URL, it holds the secret
+		}
+		return fmt.Errorf("could not post the notification: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("webhook answered %s: %s", res.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// sendNotification builds the payload of -notify and posts it, or prints it with -notify-dry-run.
+func sendNotification(format string) error {
+	summary, err := buildNotifySummary(*figs.String(argInputFile), *figs.String(argNotifyBaseline))
+	if err != nil {
+		return err
+	}
+	payload, err := renderNotifyPayload(format, *figs.String(argNotifyTemplate), summary)
+	if err != nil {
+		return err
+	}
+	if *figs.Bool(argNotifyDryRun) {
+		fmt.Println(string(payload))
+		return nil
+	}
+	webhook := *figs.String(argNotifyURL)
+	if webhook == "" {
+		return fmt.Errorf("-%s needs a webhook, set -%s or %s, or print the payload with -%s", argNotify, argNotifyURL, envNotifyURL, argNotifyDryRun)
+	}
+	return postNotification(webhook, payload)
+}
+
+// assureNotifyFormat is the figtree validator for -notify.
+func assureNotifyFormat(value interface{}) error {
+	var format string
+	switch v := value.(type) {
+	case string:
+		format = v
+	case *string:
+		if v != nil {
+			format = *v
+		}
+	}
+	if format == "" {
+		return nil
+	}
+	if !slices.Contains(sortedKeys(notifyTemplates), format) {
+		return fmt.Errorf("-%s: unknown format %q, expected one of %s", argNotify, format, strings.Join(sortedKeys(notifyTemplates), ", "))
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// webhook is an httptest server that records the payloads posted to it and answers with status.
+type webhook struct {
+	*httptest.Server
+	mu       sync.Mutex
+	payloads []string
+}
+
+func newWebhook(t *testing.T, status int, answer string) *webhook {
+	t.Helper()
+	h := &webhook{}
+	h.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		h.mu.Lock()
+		h.payloads = append(h.payloads, string(body))
+		h.mu.Unlock()
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, answer)
+	}))
+	t.Cleanup(h.Close)
+	return h
+}
+
+func (h *webhook) posted() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.payloads...)
+}
+
+// notifyReports writes the reports of a run: prod with two DANGEROUS results and a failed command.
+func notifyReports(t *testing.T, dangerous ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	report := JSONOutput{
+		State:  "s3://bucket/prod.tfstate",
+		Region: "us-east-1",
+		Results: JSONResults{
+			WarningResults: []JSONResultItem{{Resource: "aws_iam_role.r", Kind: "aws_iam_role", Message: "drift"}},
+		},
+		ExecutionLogs: []CommandExecutionLog{
+			{Command: "terraform import 'aws_s3_bucket.a' a", ExitCode: 1},
+			{Command: "terraform import 'aws_s3_bucket.a' a", ExitCode: 1},
+			{Command: "terraform state list"},
+		},
+	}
+	for _, resource := range dangerous {
+		report.Results.DangerousResults = append(report.Results.DangerousResults, JSONResultItem{Resource: resource, Kind: "aws_s3_bucket", Message: "missing in AWS"})
+	}
+	writeReport(t, dir, "prod", report)
+	return dir
+}
+
+func TestRenderNotifyPayloads(t *testing.T) {
+	summary, err := buildNotifySummary(notifyReports(t, "aws_s3_bucket.a", "aws_s3_bucket.b"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"prod (`s3://bucket/prod.tfstate`, us-east-1)", "WARNING 1, DANGEROUS 2", "2 of 3 commands failed",
+		"DANGEROUS results (2):", "• `aws_s3_bucket.b` (aws_s3_bucket) missing in AWS", "• `terraform import 'aws_s3_bucket.a' a` exit 1 ×2"} {
+		if !strings.Contains(summary.Text, want) {
+			t.Errorf("text does not contain %q:\n%s", want, summary.Text)
+		}
+	}
+
+	t.Run("slack", func(t *testing.T) {
+		payload, err := renderNotifyPayload("slack", "", summary)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var slack struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(payload, &slack); err != nil {
+			t.Fatal(err)
+		}
+		if want := "*" + appName + "*\n" + summary.Text; slack.Text != want {
+			t.Errorf("text = %q, want %q", slack.Text, want)
+		}
+	})
+	t.Run("teams", func(t *testing.T) {
+		payload, err := renderNotifyPayload("teams", "", summary)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var teams struct {
+			Type        string `json:"type"`
+			Attachments []struct {
+				ContentType string `json:"contentType"`
+				Content     struct {
+					Type string `json:"type"`
+					Body []struct {
+						Text string `json:"text"`
+					} `json:"body"`
+				} `json:"content"`
+			} `json:"attachments"`
+		}
+		if err := json.Unmarshal(payload, &teams); err != nil {
+			t.Fatal(err)
+		}
+		if teams.Type != "message" || len(teams.Attachments) != 1 || teams.Attachments[0].Content.Type != "AdaptiveCard" {
+			t.Fatalf("not an Adaptive Card message: %s", payload)
+		}
+		body := teams.Attachments[0].Content.Body
+		if len(body) != 2 || body[0].Text != appName || body[1].Text != summary.Text {
+			t.Errorf("card body = %+v", body)
+		}
+	})
+	t.Run("json", func(t *testing.T) {
+		payload, err := renderNotifyPayload("json", "", summary)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded notifySummary
+		if err := json.Unmarshal(payload, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if len(decoded.Reports) != 1 || decoded.Reports[0].DangerousTotal != 2 || decoded.Reports[0].FailedLogs != 2 || decoded.Text != summary.Text {
+			t.Errorf("decoded summary = %+v", decoded)
+		}
+	})
+}
+
+func TestRenderNotifyCustomTemplate(t *testing.T) {
+	summary, err := buildNotifySummary(notifyReports(t, `aws_s3_bucket.quote"d`), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	custom := filepath.Join(dir, "custom.tmpl")
+	if err := os.WriteFile(custom, []byte(`{"summary": {{json .Text}}, "envs": [{{range $i, $r := .Reports}}{{if $i}},{{end}}{{json $r.Env}}{{end}}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	payload, err := renderNotifyPayload("slack", custom, summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Summary string   `json:"summary"`
+		Envs    []string `json:"envs"`
+	}
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Summary != summary.Text || len(decoded.Envs) != 1 || decoded.Envs[0] != "prod" {
+		t.Errorf("decoded = %+v", decoded)
+	}
+
+	broken := filepath.Join(dir, "broken.tmpl")
+	if err := os.WriteFile(broken, []byte(`{"summary": "{{.Text}}"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := renderNotifyPayload("slack", broken, summary); err == nil || !strings.Contains(err.Error(), "did not render valid JSON") {
+		t.Errorf("broken template error = %v, want invalid JSON", err)
+	}
+}
+
+func TestNotifyNewDangerousSinceBaseline(t *testing.T) {
+	current := notifyReports(t, "aws_s3_bucket.a", "aws_s3_bucket.b", "aws_s3_bucket.c")
+	previous := notifyReports(t, "aws_s3_bucket.a", "aws_s3_bucket.c")
+
+	summary, err := buildNotifySummary(current, previous)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := summary.Reports[0]
+	if !summary.Compared || !r.Compared {
+		t.Fatalf("summary is not compared with the baseline: %+v", r)
+	}
+	if r.DangerousTotal != 1 || len(r.Dangerous) != 1 || r.Dangerous[0].Resource != "aws_s3_bucket.b" {
+		t.Errorf("new DANGEROUS = %+v, want only aws_s3_bucket.b", r.Dangerous)
+	}
+	if r.Counts["DANGEROUS"] != 3 {
+		t.Errorf("DANGEROUS count = %d, want all 3", r.Counts["DANGEROUS"])
+	}
+	if !strings.Contains(summary.Text, "New DANGEROUS results (1):") {
+		t.Errorf("text does not announce the new result:\n%s", summary.Text)
+	}
+
+	// a single baseline report of another environment is compared as well
+	other := t.TempDir()
+	if err := os.Rename(filepath.Join(previous, "report.prod.json"), filepath.Join(other, "report.staging.json")); err != nil {
+		t.Fatal(err)
+	}
+	if summary, err = buildNotifySummary(current, other); err != nil {
+		t.Fatal(err)
+	}
+	if r := summary.Reports[0]; !r.Compared || r.DangerousTotal != 1 {
+		t.Errorf("single baseline report: compared %v, %d new", r.Compared, r.DangerousTotal)
+	}
+
+	// of several reports, the baseline report of one environment is not compared with the others
+	dev := notifyReports(t, "aws_s3_bucket.a")
+	writeReport(t, current, "dev", JSONOutput{State: "s3://bucket/dev.tfstate"})
+	if err := os.Rename(filepath.Join(dev, "report.prod.json"), filepath.Join(dev, "report.dev.json")); err != nil {
+		t.Fatal(err)
+	}
+	if summary, err = buildNotifySummary(current, dev); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range summary.Reports {
+		if r.Compared != (r.Env == "dev") {
+			t.Errorf("%s compared with the dev baseline: %v", r.Env, r.Compared)
+		}
+	}
+	if prod := summary.Reports[1]; prod.Env != "prod" || prod.DangerousTotal != 3 {
+		t.Errorf("prod = %+v, want all 3 DANGEROUS results listed", prod)
+	}
+
+	same, err := buildNotifySummary(current, current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(same.Text, "No new DANGEROUS results.") {
+		t.Errorf("text of an unchanged run:\n%s", same.Text)
+	}
+}
+
+func TestPostNotification(t *testing.T) {
+	ok := newWebhook(t, http.StatusOK, "ok")
+	if err := postNotification(ok.URL+"/hooks/secret", []byte(`{"text": "hi"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if got := ok.posted(); len(got) != 1 || got[0] != `{"text": "hi"}` {
+		t.Errorf("posted = %q", got)
+	}
+
+	rejected := newWebhook(t, http.StatusBadRequest, "invalid_payload")
+	err := postNotification(rejected.URL+"/hooks/secret", []byte(`{}`))
+	if err == nil || !strings.Contains(err.Error(), "400") || !strings.Contains(err.Error(), "invalid_payload") {
+		t.Errorf("error = %v, want the 400 and the answer", err)
+	}
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	err = postNotification(closed.URL+"/hooks/secret", []byte(`{}`))
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("error = %v, want one without the webhook URL", err)
+	}
+}
+
+func TestSendNotificationDryRunNeverPosts(t *testing.T) {
+	hook := newWebhook(t, http.StatusOK, "ok")
+	setFlag(t, argInputFile, notifyReports(t, "aws_s3_bucket.a"))
+	setFlag(t, argNotifyURL, hook.URL)
+	setFlag(t, argNotifyDryRun, true)
+
+	var err error
+	out := captureStdout(t, func() { err = sendNotification("slack") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !json.Valid([]byte(out)) || !strings.Contains(out, "aws_s3_bucket.a") {
+		t.Errorf("dry run printed %q, want the payload", out)
+	}
+	if got := hook.posted(); len(got) != 0 {
+		t.Errorf("dry run posted %q", got)
+	}
+
+	setFlag(t, argNotifyDryRun, false)
+	if err := sendNotification("slack"); err != nil {
+		t.Fatal(err)
+	}
+	if got := hook.posted(); len(got) != 1 {
+		t.Errorf("posted %d payloads, want 1", len(got))
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// loadPlan reads the -plan file, the output of terraform show -json for a saved plan. No path
+// means no plan.
+func loadPlan(path string) (*terraformPlan, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p := &terraformPlan{path: path}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if p.FormatVersion == "" {
+		return nil, fmt.Errorf("%s: no format_version, expected the output of terraform show -json <planfile>", path)
+	}
+	p.actions = make(map[string]string, len(p.ResourceChanges))
+	for _, rc := range p.ResourceChanges {
+		p.actions[rc.Address] = planAction(rc.Change.Actions)
+	}
+	return p, nil
+}
+
+// planAction names the actions terraform lists for a change. A delete and a create together are
+// a replace, in either order.
+func planAction(actions []string) string {
+	switch strings.Join(actions, ",") {
+	case "no-op":
+		return planNoOp
+	case "create":
+		return planCreate
+	case "update":
+		return planUpdate
+	case "delete":
+		return planDelete
+	case "delete,create", "create,delete":
+		return planReplace
+	default:
+		return strings.Join(actions, ",")
+	}
+}
+
+// action returns what the plan does to an address. Addresses the plan does not mention have none.
+func (p *terraformPlan) action(address string) (string, bool) {
+	if p == nil {
+		return "", false
+	}
+	action, ok := p.actions[address]
+	return action, ok
+}
+
+// planConflict returns why the planned action of a result is a problem, "" when it is not.
+func planConflict(category, action string) string {
+	for _, rule := range planConflictRules {
+		if rule.category == category && rule.action == action {
+			return rule.message
+		}
+	}
+	return ""
+}
+
+// plannedResult returns the planned action of a result and the conflict it makes, if any.
+func plannedResult(category string, item JSONResultItem) (string, string) {
+	action, ok := plannedChanges.action(item.Resource)
+	if !ok {
+		return "", ""
+	}
+	return action, planConflict(category, action)
+}
+
+// planConflicts counts the results of a category whose planned action conflicts with them.
+func planConflicts(category string, results []JSONResultItem) int {
+	n := 0
+	for _, item := range results {
+		if _, conflict := plannedResult(category, item); conflict != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// planBadge renders the planned action in front of a result in the list, e.g. [plan: create].
+func planBadge(action, conflict string) string {
+	switch {
+	case conflict != "":
+		return errorStyle.Render(fmt.Sprintf("[plan: %s!]", action)) + " "
+	case action != "":
+		return helpStyle.Render(fmt.Sprintf("[plan: %s]", action)) + " "
+	}
+	return ""
+}
+
+// renderPlannedAction renders the planned action of a result for the detail views.
+func renderPlannedAction(category string, item JSONResultItem) string {
+	if plannedChanges == nil {
+		return ""
+	}
+	action, conflict := plannedResult(category, item)
+	if action == "" {
+		return fmt.Sprintf("%s %s\n", titleStyle.Render("Plan:"), helpStyle.Render("not in the plan"))
+	}
+	line := fmt.Sprintf("%s %s\n", titleStyle.Render("Plan:"), action)
+	if conflict != "" {
+		line += errorStyle.Render("Conflict: "+conflict) + "\n"
+	}
+	return line
+}
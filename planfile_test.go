@@ -0,0 +1,114 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPlanAction(t *testing.T) {
+	for _, tc := range []struct {
+		actions []string
+		want    string
+	}{
+		{[]string{"no-op"}, planNoOp},
+		{[]string{"create"}, planCreate},
+		{[]string{"update"}, planUpdate},
+		{[]string{"delete"}, planDelete},
+		{[]string{"delete", "create"}, planReplace},
+		{[]string{"create", "delete"}, planReplace},
+		{[]string{"read"}, "read"},
+		{[]string{"forget", "create"}, "forget,create"},
+		{nil, ""},
+	} {
+		if got := planAction(tc.actions); got != tc.want {
+			t.Errorf("planAction(%q) = %q, want %q", tc.actions, got, tc.want)
+		}
+	}
+}
+
+func TestPlanConflict(t *testing.T) {
+	for _, tc := range []struct{ category, action, want string }{
+		{"POTENTIAL_IMPORT", planCreate, "creates a resource that already exists in AWS"},
+		{"POTENTIAL_IMPORT", planReplace, "replaces a resource that already exists in AWS"},
+		{"OK", planDelete, "destroys a resource that is in sync"},
+		{"OK", planReplace, "replaces a resource that is in sync"},
+		{"DANGEROUS", planNoOp, "missing in AWS to be unchanged"},
+		{"DANGEROUS", planUpdate, "updates a resource that is missing in AWS"},
+	} {
+		if got := planConflict(tc.category, tc.action); !strings.Contains(got, tc.want) {
+			t.Errorf("planConflict(%s, %s) = %q, want %q", tc.category, tc.action, got, tc.want)
+		}
+	}
+	// every rule is reachable, none is shadowed by an earlier one
+	for _, rule := range planConflictRules {
+		if got := planConflict(rule.category, rule.action); got != rule.message {
+			t.Errorf("planConflict(%s, %s) = %q, want %q", rule.category, rule.action, got, rule.message)
+		}
+	}
+	for _, tc := range []struct{ category, action string }{
+		{"POTENTIAL_IMPORT", planNoOp},
+		{"POTENTIAL_IMPORT", planDelete},
+		{"OK", planNoOp},
+		{"OK", planUpdate},
+		{"OK", planCreate},
+		{"DANGEROUS", planCreate},
+		{"DANGEROUS", planReplace},
+		{"ERROR", planDelete},
+		{"WARNING", planReplace},
+	} {
+		if got := planConflict(tc.category, tc.action); got != "" {
+			t.Errorf("planConflict(%s, %s) = %q, want no conflict", tc.category, tc.action, got)
+		}
+	}
+}
+
+func TestLoadPlan(t *testing.T) {
+	write := func(t *testing.T, content string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "plan.json")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	p, err := loadPlan(write(t, `{"format_version": "1.2", "resource_changes": [
+		{"address": "aws_s3_bucket.a", "change": {"actions": ["create"]}},
+		{"address": "aws_s3_bucket.b", "change": {"actions": ["create", "delete"]}},
+		{"address": "aws_s3_bucket.c", "change": {"actions": ["no-op"]}}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for address, want := range map[string]string{"aws_s3_bucket.a": planCreate, "aws_s3_bucket.b": planReplace, "aws_s3_bucket.c": planNoOp} {
+		if got, ok := p.action(address); !ok || got != want {
+			t.Errorf("action(%s) = %q, %v, want %q", address, got, ok, want)
+		}
+	}
+	if got, ok := p.action("aws_s3_bucket.d"); ok {
+		t.Errorf("action of an address the plan does not mention = %q", got)
+	}
+
+	if p, err := loadPlan(""); p != nil || err != nil {
+		t.Errorf("loadPlan(\"\") = %v, %v, want no plan", p, err)
+	}
+	var none *terraformPlan
+	if _, ok := none.action("aws_s3_bucket.a"); ok {
+		t.Error("no plan has an action")
+	}
+
+	for _, tc := range []struct{ name, content, want string }{
+		{"state instead of plan", `{"values": {}}`, "no format_version"},
+		{"not json", `format_version = 1`, "invalid character"},
+		{"wrong type", `{"format_version": "1.2", "resource_changes": {}}`, "cannot unmarshal"},
+	} {
+		if _, err := loadPlan(write(t, tc.content)); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: loadPlan = %v, want %q", tc.name, err, tc.want)
+		}
+	}
+	if _, err := loadPlan(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("loadPlan of a missing file succeeded")
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+// importTarget returns the address and the ID that a result would be imported with: the AWS ID,
+// or the last argument of its terraform import command. Only import results have one.
+func importTarget(category string, item JSONResultItem) (string, string, bool) {
+	fields := strings.Fields(item.Command)
+	isImport := strings.Contains(item.Command, "terraform import")
+	if category != "POTENTIAL_IMPORT" && !isImport {
+		return "", "", false
+	}
+	id := item.AWSID
+	if id == "" && isImport && len(fields) > 0 {
+		id, _ = shellUnquote(fields[len(fields)-1])
+	}
+	return item.Resource, id, item.Resource != "" && id != ""
+}
+
+// hclString quotes s as an HCL string. HCL only knows the escapes \\, \", \n, \r, \t and
+// \uNNNN/\UNNNNNNNN, so every other character that is not printable is written as a \u escape.
+// Template sequences are escaped so the ID is taken literally.
+func hclString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '"':
+			b.WriteString(`\"`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r > 0xffff && !unicode.IsPrint(r):
+			b.WriteString(fmt.Sprintf(`\U%08x`, r))
+		case !unicode.IsPrint(r):
+			b.WriteString(fmt.Sprintf(`\u%04x`, r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	q := strings.ReplaceAll(b.String(), "${", "$${")
+	return strings.ReplaceAll(q, "%{", "%%{")
+}
+
+// copyTree copies the terraform directory into the scratch directory, .terraform included: a plan
+// may write to it (the backend state, the module manifest), which must not reach the real one.
+// Files keep their mode, so the provider binaries stay executable, and symlinks such as the
+// providers of a plugin cache keep pointing where they did.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		switch {
+		case rel == ".":
+			return nil
+		case d.IsDir() && d.Name() == ".git":
+			return filepath.SkipDir
+		case d.IsDir():
+			return os.MkdirAll(target, 0755)
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			if !filepath.IsAbs(link) {
+				link = filepath.Join(filepath.Dir(path), link)
+			}
+			return os.Symlink(link, target)
+		case !d.Type().IsRegular():
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, in); err != nil {
+			_ = out.Close()
+			return err
+		}
+		return out.Close()
+	})
+}
+
+// previewImport plans the import of a result in a scratch copy of the terraform directory, with a
+// generated import block and -generate-config-out, so nothing in the real directory or state
+// changes. It runs in the context of the profile, like the command it previews.
+func previewImport(address, id string, p profile) tea.Cmd {
+	return func() tea.Msg {
+		msg := planPreviewMsg{address: address}
+		tfDir, tfState := p.dirAndState()
+		if !isValidPath(tfDir) {
+			msg.err = fmt.Errorf("%s %q does not exist, set it or use a profile with a tf_dir", envTfDir, tfDir)
+			return msg
+		}
+		scratch, err := os.MkdirTemp("", appName+"-plan-*")
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+		defer os.RemoveAll(scratch)
+		if err := copyTree(tfDir, scratch); err != nil {
+			msg.err = fmt.Errorf("could not copy %s: %w", tfDir, err)
+			return msg
+		}
+		block := fmt.Sprintf("import {\n  to = %s\n  id = %s\n}\n", address, hclString(id))
+		if err := os.WriteFile(filepath.Join(scratch, planPreviewImportFile), []byte(block), 0644); err != nil {
+			msg.err = err
+			return msg
+		}
+
+		args := []string{"plan", "-input=false", "-no-color", "-generate-config-out=" + planPreviewGeneratedFile}
+		if tfState != "" {
+			// relative to the real directory, not the scratch copy
+			if !filepath.IsAbs(tfState) {
+				tfState = filepath.Join(tfDir, tfState)
+			}
+			args = append(args, "-state="+tfState)
+		}
+		cmd := exec.Command(*figs.String(argTerraform), args...)
+		cmd.Dir = scratch
+		if p.Name != "" {
+			cmd.Env = p.environ()
+		}
+		var out, errOut bytes.Buffer
+		cmd.Stdout = &out
+		cmd.Stderr = &errOut
+		msg.err = cmd.Run()
+		msg.command = fmt.Sprintf("%s %s", *figs.String(argTerraform), strings.Join(args, " "))
+		msg.stdout, msg.stderr = out.String(), errOut.String()
+
+		generated, err := os.ReadFile(filepath.Join(scratch, planPreviewGeneratedFile))
+		if err != nil && !errors.Is(err, os.ErrNotExist) && msg.err == nil {
+			msg.err = err
+		}
+		msg.generated = string(generated)
+		return msg
+	}
+}
+
+// showPlanPreview starts the plan preview of the selected result.
+func (m *model) showPlanPreview() tea.Cmd {
+	i, ok := m.resultsResourceList.SelectedItem().(resultItem)
+	if !ok {
+		return nil
+	}
+	address, id, ok := importTarget(m.activeResultCategory, i.JSONResultItem)
+	if !ok {
+		m.setNotification("Only import results with an ID can be previewed.", true)
+		return m.clearNotificationAfter(2 * time.Second)
+	}
+	p, err := m.execProfile()
+	if err != nil {
+		m.setNotification(fmt.Sprintf("Refusing to plan: %v", err), true)
+		return m.clearNotificationAfter(5 * time.Second)
+	}
+	m.planPreview = planPreviewMsg{address: address}
+	m.planRunning = true
+	m.state = m.pushView(viewPlanPreview)
+	m.renderPlanPreview()
+	return previewImport(address, id, p)
+}
+
+// renderPlanPreview renders the planned changes and the generated configuration.
+func (m *model) renderPlanPreview() {
+	pp, width := m.planPreview, m.viewPort.Width
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Import Preview: "+pp.address) + "\n\n")
+	if m.planRunning {
+		b.WriteString(fmt.Sprintf("%s Planning the import in a scratch copy of the terraform directory...\n", m.spinNer.View()))
+		m.viewPort.SetContent(b.String())
+		return
+	}
+	if pp.command != "" {
+		b.WriteString(helpStyle.Render(wordwrap.String(pp.command, width)) + "\n\n")
+	}
+	if pp.err != nil {
+		b.WriteString(errorStyle.Render(wordwrap.String(fmt.Sprintf("Plan failed: %v", pp.err), width)) + "\n\n")
+	}
+	if strings.TrimSpace(pp.stdout) != "" {
+		b.WriteString(titleStyle.Render("Plan:") + "\n")
+		b.WriteString(wordwrap.String(secrets.redact(strings.TrimSpace(pp.stdout)), width) + "\n\n")
+	}
+	if strings.TrimSpace(pp.stderr) != "" {
+		b.WriteString(titleStyle.Render("STDERR:") + "\n")
+		b.WriteString(errorStyle.Render(wordwrap.String(secrets.redact(strings.TrimSpace(pp.stderr)), width)) + "\n\n")
+	}
+	b.WriteString(titleStyle.Render("Generated Configuration:") + "\n")
+	if pp.generated == "" {
+		b.WriteString(helpStyle.Render("(none)") + "\n")
+	} else {
+		b.WriteString(secrets.redact(pp.generated) + "\n")
+	}
+	m.viewPort.SetContent(b.String())
+}
+
+func (m model) updatePlanPreviewView(msg tea.Msg) (model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.keys.Top):
+			m.viewPort.GotoTop()
+			return m, nil
+		case key.Matches(msg, m.keys.Bottom):
+			m.viewPort.GotoBottom()
+			return m, nil
+		case key.Matches(msg, m.keys.Copy):
+			if m.planRunning || m.planPreview.generated == "" {
+				m.setNotification("No generated configuration to copy.", true)
+				return m, m.clearNotificationAfter(2 * time.Second)
+			}
+			return m, copyToClipboardCmd(m.planPreview.generated)
+		}
+	}
+	var cmd tea.Cmd
+	m.viewPort, cmd = m.viewPort.Update(msg)
+	return m, cmd
+}
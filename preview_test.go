@@ -0,0 +1,141 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeTerraform is a terraform on PATH that writes everywhere a plan might: .terraform, the
+// working directory and the generated config, and fails unless the provider is executable.
+const fakeTerraform = `#!/bin/sh
+test -x .terraform/providers/registry.terraform.io/hashicorp/aws/terraform-provider-aws || { echo "provider not executable" >&2; exit 1; }
+test "$(cat .terraform/providers/cache/linked)" = "cached" || { echo "plugin cache link broken" >&2; exit 1; }
+echo changed > .terraform/terraform.tfstate
+echo '{}' > .terraform/modules/modules.json
+touch .terraform/plan-was-here
+echo changed > main.tf
+for arg in "$@"; do
+  case "$arg" in
+    -generate-config-out=*) echo 'resource "aws_s3_bucket" "b" {}' > "${arg#-generate-config-out=}" ;;
+  esac
+done
+echo "Plan: 1 to import, 0 to add, 0 to change, 0 to destroy."
+`
+
+// snapshotTree maps every path under dir to its contents, or to the link target of a symlink.
+func snapshotTree(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		if d.Type()&fs.ModeSymlink != 0 {
+			link, err := os.Readlink(path)
+			files[rel] = "-> " + link
+			return err
+		}
+		data, err := os.ReadFile(path)
+		files[rel] = string(data)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestPreviewImportLeavesTheRealDirectoryAlone(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake terraform is a shell script")
+	}
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "terraform"), []byte(fakeTerraform), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	cache := t.TempDir()
+	if err := os.WriteFile(filepath.Join(cache, "linked"), []byte("cached"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tfDir := t.TempDir()
+	provider := filepath.Join(tfDir, ".terraform", "providers", "registry.terraform.io", "hashicorp", "aws")
+	for _, dir := range []string{provider, filepath.Join(tfDir, ".terraform", "modules")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, content := range map[string]string{
+		"main.tf":                         `resource "aws_s3_bucket" "a" {}`,
+		".terraform/terraform.tfstate":    `{"backend": {"type": "s3"}}`,
+		".terraform/modules/modules.json": `{"Modules": []}`,
+		".terraform.lock.hcl":             `provider "registry.terraform.io/hashicorp/aws" {}`,
+		"terraform.tfstate":               `{"version": 4}`,
+	} {
+		if err := os.WriteFile(filepath.Join(tfDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(provider, "terraform-provider-aws"), []byte("binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(cache, filepath.Join(tfDir, ".terraform", "providers", "cache")); err != nil {
+		t.Fatal(err)
+	}
+	before := snapshotTree(t, tfDir)
+
+	msg, ok := previewImport("aws_s3_bucket.b", "bucket-b", profile{TfDir: tfDir, TfState: "terraform.tfstate"})().(planPreviewMsg)
+	if !ok {
+		t.Fatal("previewImport did not return a planPreviewMsg")
+	}
+	if msg.err != nil {
+		t.Fatalf("preview failed: %v\n%s", msg.err, msg.stderr)
+	}
+	if !strings.Contains(msg.stdout, "1 to import") {
+		t.Errorf("stdout = %q, want the plan", msg.stdout)
+	}
+	if !strings.Contains(msg.generated, `resource "aws_s3_bucket" "b"`) {
+		t.Errorf("generated = %q, want the generated config", msg.generated)
+	}
+	if want := "-state=" + filepath.Join(tfDir, "terraform.tfstate"); !strings.Contains(msg.command, want) {
+		t.Errorf("command = %q, want %s", msg.command, want)
+	}
+
+	after := snapshotTree(t, tfDir)
+	for path, content := range before {
+		if after[path] != content {
+			t.Errorf("%s changed from %q to %q", path, content, after[path])
+		}
+	}
+	for path := range after {
+		if _, ok := before[path]; !ok {
+			t.Errorf("%s was created in the real directory", path)
+		}
+	}
+}
+
+func TestHCLString(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"bucket-b", `"bucket-b"`},
+		{`a"b\c`, `"a\"b\\c"`},
+		{"line\nbreak\r\ttab", `"line\nbreak\r\ttab"`},
+		{"bell\a esc\x1b vt\v nul\x00 del\x7f", `"bell\u0007 esc\u001b vt\u000b nul\u0000 del\u007f"`},
+		{"sep\u2028 bom\ufeff", `"sep\u2028 bom\ufeff"`},
+		{"tag\U000e0001", `"tag\U000e0001"`},
+		{"naïve 東京 🚀", `"naïve 東京 🚀"`},
+		{"${var.x} %{if}", `"$${var.x} %%{if}"`},
+		{"bad\xffbyte", "\"bad\ufffdbyte\""},
+	} {
+		if got := hclString(tc.in); got != tc.want {
+			t.Errorf("hclString(%q) = %s, want %s", tc.in, got, tc.want)
+		}
+	}
+}
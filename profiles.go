@@ -0,0 +1,154 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"gopkg.in/yaml.v3"
+)
+
+// loadProfiles reads the -profiles file, a YAML map of profiles by name. It is a file of its own
+// because figtree only loads flat values from the config file. A missing file has no profiles.
+func loadProfiles(path string) (map[string]profile, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var loaded map[string]profile
+	if err := yaml.Unmarshal(data, &loaded); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	var problems []string
+	for _, name := range sortedKeys(loaded) {
+		p := loaded[name]
+		p.Name = name
+		for k := range p.Env {
+			if k == "" || strings.ContainsAny(k, "= ") {
+				problems = append(problems, fmt.Sprintf("profiles.%s.env: invalid variable name %q", name, k))
+			}
+		}
+		loaded[name] = p
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%s: %s", path, strings.Join(problems, "; "))
+	}
+	return loaded, nil
+}
+
+// expectedRegion is the region the reports of the profile must be for: report_region, or the
+// AWS region when that is not set.
+func (p profile) expectedRegion() string {
+	if p.ReportRegion != "" {
+		return p.ReportRegion
+	}
+	return p.AWSRegion
+}
+
+// dirAndState returns where commands of the profile run and which state they use. Unset fields
+// fall back to FIGS_TF_DIR and FIGS_TF_STATE.
+func (p profile) dirAndState() (string, string) {
+	tfDir, tfState := p.TfDir, p.TfState
+	if tfDir == "" {
+		tfDir = os.Getenv(envTfDir)
+	}
+	if tfState == "" {
+		tfState = os.Getenv(envTfState)
+	}
+	return tfDir, tfState
+}
+
+// environ returns the environment of commands run with the profile: the process environment with
+// the AWS profile, the region and the extra variables of the profile on top.
+func (p profile) environ() []string {
+	env := os.Environ()
+	if p.AWSProfile != "" {
+		env = append(env, "AWS_PROFILE="+p.AWSProfile)
+	}
+	if p.AWSRegion != "" {
+		env = append(env, "AWS_REGION="+p.AWSRegion, "AWS_DEFAULT_REGION="+p.AWSRegion)
+	}
+	for _, k := range sortedKeys(p.Env) {
+		env = append(env, k+"="+p.Env[k])
+	}
+	return env
+}
+
+// checkRegion refuses to run commands of the profile against a report of another region, e.g.
+// the prod profile with the dev report open.
+func (p profile) checkRegion(report *JSONOutput) error {
+	want := p.expectedRegion()
+	if want == "" {
+		return nil
+	}
+	if report.Region != want {
+		return fmt.Errorf("profile %s expects region %s, the report is for %s", p.Name, want, orDash(report.Region))
+	}
+	return nil
+}
+
+// execProfile returns the profile commands run with, and an error when the open report does not
+// belong to it. Without an active profile, commands run with the environment as before.
+func (m model) execProfile() (profile, error) {
+	if m.profile == "" {
+		return profile{}, nil
+	}
+	p, ok := profiles[m.profile]
+	if !ok {
+		return p, fmt.Errorf("unknown profile %s", m.profile)
+	}
+	return p, p.checkRegion(m.report)
+}
+
+// showProfiles opens the profile switcher.
+func (m *model) showProfiles() tea.Cmd {
+	if len(profiles) == 0 {
+		m.setNotification(fmt.Sprintf("No profiles in %s", *figs.String(argProfiles)), true)
+		return m.clearNotificationAfter(2 * time.Second)
+	}
+	items := []list.Item{profileItem{active: m.profile == ""}}
+	selected := 0
+	for _, name := range sortedKeys(profiles) {
+		if name == m.profile {
+			selected = len(items)
+		}
+		items = append(items, profileItem{profile: profiles[name], active: name == m.profile})
+	}
+	m.profileList.Title = "Profiles"
+	m.profileList.SetItems(items)
+	m.profileList.Select(selected)
+	m.state = m.pushView(viewProfiles)
+	return nil
+}
+
+func (m model) updateProfilesView(msg tea.Msg) (model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if key.Matches(msg, m.keys.Open) {
+			if i, ok := m.profileList.SelectedItem().(profileItem); ok {
+				m.profile = i.Name
+				m.goBack()
+				note, isError := fmt.Sprintf("Commands run with profile %s", i.Name), false
+				if i.Name == "" {
+					note = fmt.Sprintf("Commands run with %s and %s from the environment", envTfDir, envTfState)
+				} else if err := i.checkRegion(m.report); err != nil {
+					note, isError = fmt.Sprintf("Switched to profile %s, but %v", i.Name, err), true
+				}
+				m.setNotification(note, isError)
+				return m, m.clearNotificationAfter(3 * time.Second)
+			}
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.profileList, cmd = m.profileList.Update(msg)
+	return m, cmd
+}
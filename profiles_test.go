@@ -0,0 +1,33 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExecCommandRunsOnlyInTheProfileDirectory(t *testing.T) {
+	t.Setenv(envTfDir, "")
+	t.Setenv(envTfState, "")
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "ran")
+
+	missing := profile{Name: "prod", TfDir: filepath.Join(dir, "missing")}
+	out := execCommand("touch '"+marker+"'", missing)().(commandOutputMsg)
+	if out.err == nil || !strings.Contains(out.err.Error(), "profile prod") {
+		t.Errorf("err = %v, want the missing directory of profile prod", out.err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatal("the command ran without the profile directory")
+	}
+
+	out = execCommand("pwd", profile{Name: "dev", TfDir: dir})().(commandOutputMsg)
+	if out.err != nil {
+		t.Fatal(out.err)
+	}
+	got, _ := filepath.EvalSymlinks(strings.TrimSpace(out.stdout))
+	if want, _ := filepath.EvalSymlinks(dir); got != want {
+		t.Errorf("ran in %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// newRedactor combines the built-in detectors with the -redact patterns. It returns nil with
+// -no-redact, which leaves every text as it is.
+func newRedactor(disabled bool, patterns []string) (*redactor, error) {
+	if disabled {
+		return nil, nil
+	}
+	r := &redactor{rules: append([]redactRule{}, secretDetectors...)}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("-%s %q: %w", argRedact, pattern, err)
+		}
+		r.rules = append(r.rules, redactRule{name: "custom", re: re})
+	}
+	return r, nil
+}
+
+// redact replaces every secret in s with a [REDACTED:<detector>] marker. Detectors that match a
+// key and its value, e.g. aws_secret_access_key = ..., keep the key so the output still reads.
+func (r *redactor) redact(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	for _, rule := range r.rules {
+		replacement := fmt.Sprintf("[REDACTED:%s]", rule.name)
+		if rule.re.SubexpIndex("keep") >= 0 {
+			replacement = "${keep}" + replacement
+		}
+		s = rule.re.ReplaceAllString(s, replacement)
+	}
+	return s
+}
+
+// redactLog redacts every text of an execution log that terraform or the shell wrote.
+func (r *redactor) redactLog(log CommandExecutionLog) CommandExecutionLog {
+	if r == nil {
+		return log
+	}
+	log.Command = r.redact(log.Command)
+	log.Stdout = r.redact(log.Stdout)
+	log.Stderr = r.redact(log.Stderr)
+	log.Error = r.redact(log.Error)
+	return log
+}
+
+// redactResults redacts the messages of the results. Suggested commands are left alone, they are
+// generated by reconcile-tfstate and must still run.
+func (r *redactor) redactResults(results *JSONResults) {
+	if r == nil {
+		return
+	}
+	for _, cat := range resultCategories {
+		items := results.GetCategory(cat)
+		for i := range items {
+			items[i].Message = r.redact(items[i].Message)
+		}
+	}
+}
+
+// assureRedactPatterns is the figtree validator for -redact.
+func assureRedactPatterns(value interface{}) error {
+	var patterns []string
+	switch v := value.(type) {
+	case []string:
+		patterns = v
+	case *[]string:
+		if v != nil {
+			patterns = *v
+		}
+	}
+	_, err := newRedactor(false, patterns)
+	return err
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// shellFields splits a command into its arguments like a shell: whitespace separates them unless
+// it is quoted, and the quotes are removed. The operators ;, && and || are fields of their own.
+func shellFields(command string) ([]string, error) {
+	var fields []string
+	var raw strings.Builder
+	var quote byte
+	flush := func() error {
+		if raw.Len() == 0 {
+			return nil
+		}
+		field, err := shellUnquote(raw.String())
+		if err != nil {
+			return err
+		}
+		fields = append(fields, field)
+		raw.Reset()
+		return nil
+	}
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' && i+1 < len(command) {
+				raw.WriteByte(c)
+				i++
+				c = command[i]
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '\\' && i+1 < len(command):
+			raw.WriteByte(c)
+			i++
+			c = command[i]
+		case c == ' ' || c == '\t' || c == '\n':
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			continue
+		case c == ';' || (c == '&' || c == '|') && i+1 < len(command) && command[i+1] == c:
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			op := string(c)
+			if c != ';' {
+				op += string(c)
+				i++
+			}
+			fields = append(fields, op)
+			continue
+		}
+		raw.WriteByte(c)
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	return fields, flush()
+}
+
+// stateCommands finds the terraform state mv and rm calls of a command line, also when they are
+// chained with && or ;. Dry runs change nothing and are left out.
+func stateCommands(command string) []stateCommand {
+	fields, err := shellFields(command)
+	if err != nil {
+		return nil
+	}
+	var found []stateCommand
+	start := 0
+	for i := 0; i <= len(fields); i++ {
+		if i < len(fields) && fields[i] != "&&" && fields[i] != ";" && fields[i] != "||" {
+			continue
+		}
+		if c, ok := parseStateCommand(fields[start:i]); ok {
+			found = append(found, c)
+		}
+		start = i + 1
+	}
+	return found
+}
+
+// parseStateCommand reads "[VAR=value...] terraform [flags] state mv|rm [flags] ADDRESS...".
+func parseStateCommand(fields []string) (stateCommand, bool) {
+	var positional []string
+	terraform := false
+	for _, field := range fields {
+		switch {
+		case !terraform && filepath.Base(field) == "terraform":
+			terraform = true
+		case !terraform && !strings.Contains(field, "="):
+			// another program, such as echo terraform state rm
+			return stateCommand{}, false
+		case !terraform:
+		case field == "-dry-run" || field == "-dry-run=true":
+			return stateCommand{}, false
+		case strings.HasPrefix(field, "-"):
+		default:
+			positional = append(positional, field)
+		}
+	}
+	if len(positional) < 3 || positional[0] != "state" {
+		return stateCommand{}, false
+	}
+	c := stateCommand{verb: positional[1], args: positional[2:]}
+	switch {
+	case c.verb == "mv" && len(c.args) == 2, c.verb == "rm":
+		return c, true
+	}
+	return stateCommand{}, false
+}
+
+// collectRefactor replays the successful state mv and rm calls of the logs, in the order they ran,
+// into moved and removed blocks. A chain of moves collapses into one from its first to its last
+// address, a move back to where it started disappears, and a resource moved and then removed is
+// removed from its original address.
+func collectRefactor(logs []CommandExecutionLog) refactorBlocks {
+	var r refactorBlocks
+	current := make(map[string]int) // address a move ended at, index into r.moves
+	dropped := make(map[int]bool)
+	removed := make(map[string]bool)
+	for _, log := range logs {
+		if log.ExitCode != 0 || log.Error != "" {
+			continue
+		}
+		for _, c := range stateCommands(log.Command) {
+			switch c.verb {
+			case "mv":
+				from, to := c.args[0], c.args[1]
+				if i, ok := current[from]; ok {
+					delete(current, from)
+					r.moves[i].to = to
+					current[to] = i
+					continue
+				}
+				r.moves = append(r.moves, stateMove{from: from, to: to})
+				current[to] = len(r.moves) - 1
+			case "rm":
+				for _, address := range c.args {
+					if i, ok := current[address]; ok {
+						delete(current, address)
+						dropped[i] = true
+						address = r.moves[i].from
+					}
+					if !removed[address] {
+						removed[address] = true
+						r.removed = append(r.removed, address)
+					}
+				}
+			}
+		}
+	}
+	moves := r.moves[:0]
+	for i, mv := range r.moves {
+		if !dropped[i] && mv.from != mv.to {
+			moves = append(moves, mv)
+		}
+	}
+	r.moves = moves
+	return r
+}
+
+// moveCycles returns the moves that form a cycle, such as the a -> b and b -> a that a swap through
+// a temporary address leaves, by index into moves, with the cycle each is part of.
+func moveCycles(moves []stateMove) map[int]string {
+	next := make(map[string]string, len(moves))
+	for _, mv := range moves {
+		next[mv.from] = mv.to
+	}
+	cycles := make(map[int]string)
+	for i, mv := range moves {
+		chain := []string{mv.from}
+		for address, ok := mv.to, true; ok && len(chain) <= len(moves); address, ok = next[address] {
+			chain = append(chain, address)
+			if address == mv.from {
+				cycles[i] = strings.Join(chain, " -> ")
+				break
+			}
+		}
+	}
+	return cycles
+}
+
+// renderRefactor writes the blocks as HCL. Terraform follows chains of moved blocks, so a move
+// into an address that an earlier move vacated would be read as one longer move; those are
+// marked for a separate change. Terraform rejects cyclic moved blocks, those are only comments.
+func renderRefactor(path string, r refactorBlocks) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("# Generated by %s from the successful terraform state mv and rm commands of\n# %s\n", appName, path))
+	if len(r.moves) == 0 && len(r.removed) == 0 {
+		b.WriteString("# (none)\n")
+		return b.String()
+	}
+	cycles := moveCycles(r.moves)
+	vacated := make(map[string]bool)
+	for i, mv := range r.moves {
+		b.WriteString("\n")
+		if cycle, ok := cycles[i]; ok {
+			b.WriteString(fmt.Sprintf("# %s is a cycle, terraform rejects cyclic moved blocks.\n", cycle))
+			b.WriteString("# Move through a temporary address in separate changes instead:\n")
+			b.WriteString(fmt.Sprintf("# moved {\n#   from = %s\n#   to   = %s\n# }\n", mv.from, mv.to))
+			continue
+		}
+		if vacated[mv.to] {
+			b.WriteString(fmt.Sprintf("# %s was moved away above, terraform would chain this block onto that one.\n# Apply it in a separate change.\n", mv.to))
+		}
+		vacated[mv.from] = true
+		b.WriteString(fmt.Sprintf("moved {\n  from = %s\n  to   = %s\n}\n", mv.from, mv.to))
+	}
+	for _, address := range r.removed {
+		if strings.Contains(address, "[") {
+			b.WriteString(fmt.Sprintf("\n# terraform state rm %s: a removed block cannot name a single instance,\n# remove it from the state of the other workspaces by hand.\n", address))
+			continue
+		}
+		// state rm forgets the resource, it does not destroy it
+		b.WriteString(fmt.Sprintf("\nremoved {\n  from = %s\n\n  lifecycle {\n    destroy = false\n  }\n}\n", address))
+	}
+	return b.String()
+}
+
+// exportRefactor prints the moved and removed blocks of every -input report, for -refactor.
+func exportRefactor() error {
+	paths, err := resolveReportInputs(*figs.String(argInputFile))
+	if err != nil {
+		return err
+	}
+	for i, path := range paths {
+		report, err := loadReportData(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Print(renderRefactor(path, collectRefactor(report.ExecutionLogs)))
+	}
+	return nil
+}
+
+// writeRefactor writes the moved and removed blocks of the active report next to it, for the TUI.
+func (m model) writeRefactor() (string, error) {
+	out := strings.TrimSuffix(m.active.path, filepath.Ext(m.active.path)) + refactorSuffix
+	content := renderRefactor(m.active.path, collectRefactor(m.report.ExecutionLogs))
+	return out, os.WriteFile(out, []byte(content), 0644)
+}
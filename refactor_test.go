@@ -0,0 +1,120 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestShellFields(t *testing.T) {
+	for _, tc := range []struct {
+		command string
+		want    []string
+	}{
+		{`terraform state mv a b`, []string{"terraform", "state", "mv", "a", "b"}},
+		{`terraform state mv 'aws_s3_bucket.b["x y"]' "aws_s3_bucket.c[\"x y\"]"`, []string{"terraform", "state", "mv", `aws_s3_bucket.b["x y"]`, `aws_s3_bucket.c["x y"]`}},
+		{`cd infra && terraform state rm a;terraform state rm b || true`, []string{"cd", "infra", "&&", "terraform", "state", "rm", "a", ";", "terraform", "state", "rm", "b", "||", "true"}},
+		{`terraform state mv a\ b c`, []string{"terraform", "state", "mv", "a b", "c"}},
+	} {
+		got, err := shellFields(tc.command)
+		if err != nil {
+			t.Errorf("shellFields(%q): %v", tc.command, err)
+			continue
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("shellFields(%q) = %q, want %q", tc.command, got, tc.want)
+		}
+	}
+	if _, err := shellFields(`terraform state mv 'a b`); err == nil {
+		t.Error("shellFields accepted an unterminated quote")
+	}
+}
+
+func TestParseStateCommand(t *testing.T) {
+	for _, tc := range []struct {
+		command string
+		want    *stateCommand
+	}{
+		{"terraform state mv a b", &stateCommand{verb: "mv", args: []string{"a", "b"}}},
+		{"/usr/local/bin/terraform -chdir=infra state mv -lock=false a b", &stateCommand{verb: "mv", args: []string{"a", "b"}}},
+		{"terraform state rm a b c", &stateCommand{verb: "rm", args: []string{"a", "b", "c"}}},
+		{"terraform state mv -dry-run a b", nil},
+		{"terraform state rm -dry-run=true a", nil},
+		{"terraform state mv a", nil},
+		{"terraform state list", nil},
+		{"terraform import a b", nil},
+		{"TF_LOG=debug terraform state rm a", &stateCommand{verb: "rm", args: []string{"a"}}},
+		{"echo terraform state rm a", nil},
+	} {
+		fields, _ := shellFields(tc.command)
+		got, ok := parseStateCommand(fields)
+		switch {
+		case tc.want == nil && ok:
+			t.Errorf("parseStateCommand(%q) = %+v, want none", tc.command, got)
+		case tc.want != nil && (!ok || got.verb != tc.want.verb || !slices.Equal(got.args, tc.want.args)):
+			t.Errorf("parseStateCommand(%q) = %+v, %v, want %+v", tc.command, got, ok, *tc.want)
+		}
+	}
+}
+
+func TestCollectRefactor(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		commands []string
+		moves    []string // from -> to
+		removed  []string
+	}{
+		{"chain collapses", []string{"terraform state mv a b", "terraform state mv b c"}, []string{"a -> c"}, nil},
+		{"move back disappears", []string{"terraform state mv a b", "terraform state mv b a"}, nil, nil},
+		{"moved then removed", []string{"terraform state mv a b", "terraform state rm b"}, nil, []string{"a"}},
+		{"&& and ; chains", []string{"terraform state mv a b && terraform state mv c d; terraform state rm e"}, []string{"a -> b", "c -> d"}, []string{"e"}},
+		{"quoted indexed addresses", []string{`terraform state mv 'aws_s3_bucket.b["x"]' 'aws_s3_bucket.c["x"]'`, `terraform state rm "aws_iam_role.r[0]"`},
+			[]string{`aws_s3_bucket.b["x"] -> aws_s3_bucket.c["x"]`}, []string{"aws_iam_role.r[0]"}},
+		{"dry runs are skipped", []string{"terraform state mv -dry-run a b", "terraform state rm -dry-run c"}, nil, nil},
+		{"removed once", []string{"terraform state rm a", "terraform state rm a"}, nil, []string{"a"}},
+		{"swap through a temporary address", []string{"terraform state mv a tmp", "terraform state mv b a", "terraform state mv tmp b"}, []string{"a -> b", "b -> a"}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var logs []CommandExecutionLog
+			for _, command := range tc.commands {
+				logs = append(logs, CommandExecutionLog{Command: command})
+			}
+			// failed commands never count
+			logs = append(logs, CommandExecutionLog{Command: "terraform state mv x y", ExitCode: 1}, CommandExecutionLog{Command: "terraform state rm z", Error: "locked"})
+
+			r := collectRefactor(logs)
+			var moves []string
+			for _, mv := range r.moves {
+				moves = append(moves, mv.from+" -> "+mv.to)
+			}
+			if !slices.Equal(moves, tc.moves) {
+				t.Errorf("moves = %q, want %q", moves, tc.moves)
+			}
+			if !slices.Equal(r.removed, tc.removed) {
+				t.Errorf("removed = %q, want %q", r.removed, tc.removed)
+			}
+		})
+	}
+}
+
+func TestRenderRefactorCycles(t *testing.T) {
+	out := renderRefactor("report.prod.json", refactorBlocks{moves: []stateMove{{"a", "b"}, {"b", "a"}, {"c", "d"}}})
+	for _, want := range []string{
+		"# a -> b -> a is a cycle, terraform rejects cyclic moved blocks.\n",
+		"# b -> a -> b is a cycle",
+		"# moved {\n#   from = a\n#   to   = b\n# }\n",
+		"moved {\n  from = c\n  to   = d\n}\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "\nmoved {\n  from = a") || strings.Contains(out, "separate change.") {
+		t.Errorf("the cycle is written as blocks or with the chain note:\n%s", out)
+	}
+
+	out = renderRefactor("report.prod.json", refactorBlocks{moves: []stateMove{{"a", "b"}, {"c", "a"}}})
+	if !strings.Contains(out, "# a was moved away above") || strings.Contains(out, "cycle") {
+		t.Errorf("a move into a vacated address is not marked as a chain:\n%s", out)
+	}
+}
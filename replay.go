@@ -0,0 +1,155 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// replayStep names a command of an environment in the done markers. The same command gets the
+// same marker, so it runs once however often it was logged, and never counts as done for another
+// environment that shares the marker directory.
+func replayStep(env, command string) string {
+	sum := sha256.Sum256([]byte(env + "\x00" + command))
+	return hex.EncodeToString(sum[:8])
+}
+
+// renderReplay writes a bash script that runs the logged commands of one report again the way
+// execCommand does: in the terraform directory, with -state added unless the command has one. The
+// directory and the state are the ones of the profile when the script is written, the FIGS_
+// variables of whoever runs it later cannot point it at another state. Every command that
+// succeeds leaves a marker, so a script that stopped halfway can be run again. Commands that
+// failed originally, or that lost a secret to redaction, are kept as comments for the reviewer.
+func renderReplay(src replaySource, p profile) string {
+	tfDir, tfState := p.dirAndState()
+	env := reportEnvironment(src.path)
+	var b strings.Builder
+	b.WriteString("#!/usr/bin/env bash\n")
+	b.WriteString(fmt.Sprintf("# Replay of execution logs, generated by %s on %s.\n", appName, time.Now().UTC().Format(time.RFC3339)))
+	b.WriteString("# Commands that succeeded leave a marker in REPLAY_DONE_DIR and are skipped when the script runs again.\n")
+	b.WriteString("set -euo pipefail\n\n")
+	b.WriteString(fmt.Sprintf("TF_DIR=%s\n", shellQuote(tfDir)))
+	b.WriteString(fmt.Sprintf("TF_STATE=%s\n", shellQuote(tfState)))
+	b.WriteString("REPLAY_DONE_DIR=\"${REPLAY_DONE_DIR:-$PWD/" + appName + "-replay." + shellSafeName(env) + ".done}\"\n")
+	if p.Name != "" {
+		b.WriteString(fmt.Sprintf("\n# profile %s\n", p.Name))
+		if p.AWSProfile != "" {
+			b.WriteString(fmt.Sprintf("export AWS_PROFILE=%s\n", shellQuote(p.AWSProfile)))
+		}
+		if p.AWSRegion != "" {
+			b.WriteString(fmt.Sprintf("export AWS_REGION=%s AWS_DEFAULT_REGION=%s\n", shellQuote(p.AWSRegion), shellQuote(p.AWSRegion)))
+		}
+		for _, k := range sortedKeys(p.Env) {
+			b.WriteString(fmt.Sprintf("export %s=%s\n", k, shellQuote(p.Env[k])))
+		}
+	}
+	b.WriteString(`
+if [ -n "$TF_DIR" ]; then
+  if [ ! -d "$TF_DIR" ]; then
+    echo "terraform directory $TF_DIR does not exist, nothing was replayed" >&2
+    exit 1
+  fi
+  cd "$TF_DIR"
+fi
+mkdir -p "$REPLAY_DONE_DIR"
+
+# run STEP COMMAND runs the command unless it succeeded before, with -state added like the reader does.
+run() {
+  local step="$1" cmd="$2"
+  if [ -e "$REPLAY_DONE_DIR/$step" ]; then
+    echo "skip (done): $cmd"
+    return 0
+  fi
+  if [ -n "$TF_STATE" ] && [[ "$cmd" != *-state=* ]]; then
+    cmd="${cmd/terraform/terraform -state=$(printf %q "$TF_STATE")}"
+  fi
+  echo "+ $cmd"
+  sh -c "$cmd"
+  touch "$REPLAY_DONE_DIR/$step"
+}
+`)
+	b.WriteString(fmt.Sprintf("\n# --- %s", strings.ReplaceAll(src.path, "\n", " ")))
+	if src.report.StateChecksum != "" {
+		b.WriteString(fmt.Sprintf(" (state %s, checksum %s)", src.report.State, src.report.StateChecksum))
+	}
+	b.WriteString("\n")
+	for _, log := range src.logs {
+		b.WriteString(fmt.Sprintf("\n# exit %d originally", log.ExitCode))
+		for _, part := range []string{log.Source, log.TerraformAddress} {
+			if part != "" {
+				b.WriteString(", " + part)
+			}
+		}
+		b.WriteString("\n")
+		switch {
+		case log.ExitCode != 0 || log.Error != "":
+			b.WriteString("# not replayed, it failed:\n# " + strings.ReplaceAll(log.Command, "\n", "\n# ") + "\n")
+		case strings.Contains(log.Command, "[REDACTED:"):
+			b.WriteString("# not replayed, a secret was redacted from it:\n# " + strings.ReplaceAll(log.Command, "\n", "\n# ") + "\n")
+		default:
+			b.WriteString(fmt.Sprintf("run %s %s\n", replayStep(env, log.Command), shellQuote(log.Command)))
+		}
+	}
+	return b.String()
+}
+
+// shellSafeName keeps letters, digits, dots, dashes and underscores of a name and replaces the
+// rest, so it can go into a double-quoted path of the script.
+func shellSafeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// exportReplay prints a replay script of the -input report, with the logs -contains selects, for
+// -replay. The script runs in one terraform directory against one state, so it is written for a
+// single report.
+func exportReplay() error {
+	paths, err := resolveReportInputs(*figs.String(argInputFile))
+	if err != nil {
+		return err
+	}
+	if len(paths) > 1 {
+		return fmt.Errorf("-%s replays one report in one terraform directory, but -%s matches %d reports, write a script per report", argReplay, argInputFile, len(paths))
+	}
+	path := paths[0]
+	p := profiles[*figs.String(argProfile)] // main checked that it exists
+	report, err := loadReportData(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := p.checkRegion(report); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	contains := *figs.String(argCommandContains)
+	src := replaySource{path: path, report: report}
+	for _, log := range report.ExecutionLogs {
+		if strings.Contains(log.Command, contains) {
+			src.logs = append(src.logs, log)
+		}
+	}
+	fmt.Print(renderReplay(src, p))
+	return nil
+}
+
+// writeReplay writes a replay script of the logs the list shows, in the order they ran, next to
+// the report.
+func (m model) writeReplay() (string, error) {
+	p, err := m.execProfile()
+	if err != nil {
+		return "", err
+	}
+	m.logSort = sortByReportOrder
+	logs, _ := m.visibleLogs()
+	out := strings.TrimSuffix(m.active.path, filepath.Ext(m.active.path)) + replaySuffix
+	script := renderReplay(replaySource{path: m.active.path, report: m.report, logs: logs}, p)
+	return out, os.WriteFile(out, []byte(script), 0755)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderReplayIsScopedToItsReport(t *testing.T) {
+	t.Setenv(envTfDir, "/from/the/shell")
+	log := CommandExecutionLog{Command: "terraform import 'aws_s3_bucket.b' bucket-b"}
+	p := profile{Name: "prod", TfDir: "/srv/terraform/prod", TfState: "prod.tfstate"}
+	dev := renderReplay(replaySource{path: "reports/report.dev.json", report: &JSONOutput{}, logs: []CommandExecutionLog{log}}, p)
+	prod := renderReplay(replaySource{path: "reports/report.prod.json", report: &JSONOutput{}, logs: []CommandExecutionLog{log}}, p)
+
+	for _, want := range []string{"TF_DIR='/srv/terraform/prod'\n", "TF_STATE='prod.tfstate'\n", "tf-reconcile-reader-replay.prod.done"} {
+		if !strings.Contains(prod, want) {
+			t.Errorf("script does not contain %q:\n%s", want, prod)
+		}
+	}
+	if strings.Contains(prod, envTfDir) {
+		t.Errorf("script reads %s:\n%s", envTfDir, prod)
+	}
+	if step := replayStep("prod", log.Command); !strings.Contains(prod, "run "+step+" ") || strings.Contains(dev, step) {
+		t.Errorf("the step marker of prod is not unique to prod")
+	}
+}
+
+func TestReplayStopsWithoutItsTerraformDirectory(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not installed")
+	}
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "ran")
+	log := CommandExecutionLog{Command: "touch '" + marker + "'"}
+	script := renderReplay(replaySource{path: "report.prod.json", report: &JSONOutput{}, logs: []CommandExecutionLog{log}},
+		profile{Name: "prod", TfDir: filepath.Join(dir, "missing")})
+
+	cmd := exec.Command("bash", "-c", script)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+		t.Fatalf("script exited with %v, want exit 1:\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "does not exist") {
+		t.Errorf("output = %q, want the missing directory", out)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("the script ran a command outside its terraform directory")
+	}
+}
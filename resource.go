@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/andreimerlescu/prettyboy/prettyboy"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+// address returns the terraform address of a state instance, e.g. module.a.aws_s3_bucket.b["x"].
+func (r tfStateResource) address(instance tfStateInstance) string {
+	address := r.Type + "." + r.Name
+	if r.Mode == "data" {
+		address = "data." + address
+	}
+	if r.Module != "" {
+		address = r.Module + "." + address
+	}
+	switch key := instance.IndexKey.(type) {
+	case string:
+		address += fmt.Sprintf("[%q]", key)
+	case float64:
+		address += fmt.Sprintf("[%d]", int(key))
+	}
+	return address
+}
+
+// loadStateEntries reads the local state file of a report and indexes the attributes of every
+// resource instance by address.
+func loadStateEntries(path string) (map[string]json.RawMessage, error) {
+	if path == "" {
+		return nil, fmt.Errorf("the report has no local state file")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var state tfState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	entries := make(map[string]json.RawMessage)
+	for _, resource := range state.Resources {
+		for _, instance := range resource.Instances {
+			entries[resource.address(instance)] = instance.Attributes
+		}
+	}
+	return entries, nil
+}
+
+// stateEntry returns the state attributes of address from the local state file of the active
+// report. The state file is read once per report and again after a reload.
+func (m *model) stateEntry(address string) (json.RawMessage, error) {
+	ws := m.active
+	if ws.state == nil && ws.stateErr == nil {
+		ws.state, ws.stateErr = loadStateEntries(ws.report.LocalStateFile)
+	}
+	if ws.stateErr != nil {
+		return nil, ws.stateErr
+	}
+	return ws.state[address], nil
+}
+
+// logTouches reports whether an execution log was run against address.
+func logTouches(log CommandExecutionLog, address string) bool {
+	if log.TerraformAddress != "" {
+		return log.TerraformAddress == address
+	}
+	return commandMentions(log.Command, address)
+}
+
+// showResource opens the resource view of address.
+func (m *model) showResource(address string) tea.Cmd {
+	if address == "" {
+		m.setNotification("No terraform address to show", true)
+		return m.clearNotificationAfter(2 * time.Second)
+	}
+	m.activeResource = address
+	m.state = m.pushView(viewResource)
+	m.renderResource()
+	return nil
+}
+
+// renderResource brings together everything known about the active resource: its results, the
+// execution logs that touched it, its state entry and the commands run for it in this session.
+func (m *model) renderResource() {
+	address := m.activeResource
+	width := m.viewPort.Width
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%s %s\n\n", titleStyle.Render("Resource:"), address))
+
+	b.WriteString(titleStyle.Render("RESULTS:") + "\n")
+	found := false
+	for _, cat := range resultCategories {
+		for _, item := range m.report.Results.GetCategory(cat) {
+			if item.Resource != address {
+				continue
+			}
+			found = true
+			style := valueStyle
+			if cat == "DANGEROUS" || cat == "ERROR" {
+				style = errorStyle
+			}
+			b.WriteString(fmt.Sprintf("%s %s\n", style.Render(cat), item.Kind))
+			if suppression := renderSuppression(cat, item); suppression != "" {
+				b.WriteString("  " + suppression)
+			}
+			if planned := renderPlannedAction(cat, item); planned != "" {
+				b.WriteString("  " + strings.ReplaceAll(strings.TrimSuffix(planned, "\n"), "\n", "\n  ") + "\n")
+			}
+			idStyle := valueStyle
+			if item.TFID != item.AWSID {
+				idStyle = errorStyle
+			}
+			b.WriteString(fmt.Sprintf("  Terraform ID: %s\n", idStyle.Render(orDash(item.TFID))))
+			b.WriteString(fmt.Sprintf("  AWS ID:       %s\n", idStyle.Render(orDash(item.AWSID))))
+			b.WriteString(wordwrap.String("  "+item.Message, width) + "\n")
+			if item.Command != "" {
+				b.WriteString(wordwrap.String("  "+prettyboy.Command(item.Command), width) + "\n")
+			}
+		}
+	}
+	if !found {
+		b.WriteString(helpStyle.Render("No result for this address.") + "\n")
+	}
+
+	var logs []CommandExecutionLog
+	for _, log := range m.report.ExecutionLogs {
+		if logTouches(log, address) {
+			logs = append(logs, log)
+		}
+	}
+	b.WriteString("\n" + titleStyle.Render(fmt.Sprintf("EXECUTION LOGS (%d):", len(logs))) + "\n")
+	for _, log := range logs {
+		b.WriteString(logSummaryLine(log, width) + "\n")
+	}
+
+	b.WriteString("\n" + titleStyle.Render("STATE ENTRY:") + "\n")
+	attributes, err := m.stateEntry(address)
+	switch {
+	case err != nil:
+		b.WriteString(helpStyle.Render(fmt.Sprintf("Could not read the local state file: %v", err)) + "\n")
+	case attributes == nil:
+		b.WriteString(helpStyle.Render(fmt.Sprintf("Not in %s", m.report.LocalStateFile)) + "\n")
+	default:
+		var indented bytes.Buffer
+		if json.Indent(&indented, attributes, "", "  ") != nil {
+			indented.Reset()
+			indented.Write(attributes)
+		}
+		b.WriteString(secrets.redact(indented.String()) + "\n")
+	}
+
+	var runs []sessionRun
+	for _, run := range m.sessionRuns {
+		if run.log.TerraformAddress == address {
+			runs = append(runs, run)
+		}
+	}
+	b.WriteString("\n" + titleStyle.Render(fmt.Sprintf("RUNS THIS SESSION (%d):", len(runs))) + "\n")
+	for _, run := range runs {
+		b.WriteString(fmt.Sprintf("%s %s\n", run.at.Format("15:04:05"), logSummaryLine(run.log, width-9)))
+	}
+	m.viewPort.SetContent(b.String())
+	m.viewPort.GotoTop()
+}
+
+// logSummaryLine renders an execution log as one line: exit code, source and command.
+func logSummaryLine(log CommandExecutionLog, width int) string {
+	exit := valueStyle.Render(fmt.Sprintf("exit %d", log.ExitCode))
+	if log.ExitCode != 0 {
+		exit = errorStyle.Render(fmt.Sprintf("exit %d", log.ExitCode))
+	}
+	line := fmt.Sprintf("%-14s %s", orDash(log.Source), log.Command)
+	return exit + " " + truncateRunes(line, width-8)
+}
+
+// orDash returns s, or "-" when it is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func (m model) updateResourceView(msg tea.Msg) (model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, m.keys.Copy):
+			return m, copyToClipboardCmd(m.activeResource)
+		}
+	}
+	var cmd tea.Cmd
+	m.viewPort, cmd = m.viewPort.Update(msg)
+	return m, cmd
+}
@@ -8,8 +8,7 @@ import (
 )
 
 func run() error {
-	// Load data from the input file
-	report, err := loadReportData(*figs.String(argInputFile))
+	paths, err := resolveReportInputs(*figs.String(argInputFile))
 	if err != nil {
 		return err
 	}
@@ -19,22 +18,37 @@ func run() error {
 	fmt.Printf("FILTER: %s\n", containsFilter)
 	var filteredLogs []CommandExecutionLog
 
-	for _, log := range report.ExecutionLogs {
-		log.Stderr = strings.ReplaceAll(log.Stderr, "%0A", "\n")
-		log.Stdout = strings.ReplaceAll(log.Stdout, "%0A", "\n")
-		a := strings.Contains(log.Command, containsFilter)
-		b := strings.Contains(log.Stderr, containsFilter)
-		c := strings.Contains(log.Stdout, containsFilter)
-		if containsFilter == "" || a || b || c {
-			filteredLogs = append(filteredLogs, log)
+	for _, path := range paths {
+		// Load data from the input file
+		report, err := loadReportData(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
 		}
+		if len(paths) > 1 && !*figs.Bool(argJsonOutput) {
+			fmt.Printf("ENVIRONMENT: %s (%s)\n", reportEnvironment(path), path)
+		}
+
+		var envLogs []CommandExecutionLog
+		for _, log := range report.ExecutionLogs {
+			log.Stderr = strings.ReplaceAll(log.Stderr, "%0A", "\n")
+			log.Stdout = strings.ReplaceAll(log.Stdout, "%0A", "\n")
+			a := strings.Contains(log.Command, containsFilter)
+			b := strings.Contains(log.Stderr, containsFilter)
+			c := strings.Contains(log.Stdout, containsFilter)
+			if containsFilter == "" || a || b || c {
+				envLogs = append(envLogs, log)
+			}
+		}
+		if !*figs.Bool(argJsonOutput) {
+			printText(envLogs)
+		}
+		filteredLogs = append(filteredLogs, envLogs...)
 	}
 
 	// Output results in the desired format
 	if *figs.Bool(argJsonOutput) {
 		return printJSON(filteredLogs)
 	}
-	printText(filteredLogs)
 	return nil
 }
 
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// reportVersion returns the bfsm version stored in the raw report fields, or oldestReportVersion
+// when the report predates the version field.
+func reportVersion(fields map[string]json.RawMessage) (string, []schemaIssue) {
+	raw, ok := fields["version"]
+	if !ok {
+		return oldestReportVersion, []schemaIssue{{Path: "version", Message: "missing, assuming the oldest report format", Severity: issueWarning}}
+	}
+	var version string
+	if err := json.Unmarshal(raw, &version); err != nil || version == "" {
+		return oldestReportVersion, []schemaIssue{{Path: "version", Message: "not a version string, assuming the oldest report format", Severity: issueWarning}}
+	}
+	if _, ok := parseVersion(version); !ok {
+		return oldestReportVersion, []schemaIssue{{Path: "version", Message: fmt.Sprintf("cannot parse %q, assuming the oldest report format", version), Severity: issueWarning}}
+	}
+	return version, nil
+}
+
+// migrationsFor returns the migrations that apply to a report written by the given version, oldest first.
+func migrationsFor(version string) []reportMigration {
+	var applicable []reportMigration
+	for _, migration := range reportMigrations {
+		if compareVersions(version, migration.before) < 0 {
+			applicable = append(applicable, migration)
+		}
+	}
+	return applicable
+}
+
+// migrateFields renames legacy keys in place. A legacy key is only renamed when the current key
+// is absent, so a report that already carries both is left for validation to complain about.
+func migrateFields(fields map[string]json.RawMessage, renames map[string]string, path string) []schemaIssue {
+	var issues []schemaIssue
+	for _, oldKey := range sortedKeys(renames) {
+		newKey := renames[oldKey]
+		raw, ok := fields[oldKey]
+		if !ok {
+			continue
+		}
+		if _, exists := fields[newKey]; exists {
+			continue
+		}
+		fields[newKey] = raw
+		delete(fields, oldKey)
+		issues = append(issues, schemaIssue{Path: joinPath(path, oldKey), Message: fmt.Sprintf("legacy field migrated to %q", newKey)})
+	}
+	return issues
+}
+
+// migrateReport detects the report version and applies every migration for older formats to the
+// top-level fields.
+func migrateReport(fields map[string]json.RawMessage) []schemaIssue {
+	version, issues := reportVersion(fields)
+	for _, migration := range migrationsFor(version) {
+		issues = append(issues, migrateFields(fields, migration.renames, "")...)
+	}
+	return issues
+}
+
+// logRenamesFor merges the execution log renames of every migration that applies to version.
+func logRenamesFor(version string) map[string]string {
+	renames := make(map[string]string)
+	for _, migration := range migrationsFor(version) {
+		for oldKey, newKey := range migration.logRenames {
+			renames[oldKey] = newKey
+		}
+	}
+	return renames
+}
+
+// logsKeyFor returns every top-level key that holds the execution logs for a report of the given version.
+func logsKeyFor(version string) map[string]bool {
+	keys := map[string]bool{"execution_logs": true}
+	for _, migration := range migrationsFor(version) {
+		for oldKey, newKey := range migration.renames {
+			if newKey == "execution_logs" {
+				keys[oldKey] = true
+			}
+		}
+	}
+	return keys
+}
+
+// decodeExecutionLog decodes one execution log, renaming legacy fields first when needed.
+func decodeExecutionLog(raw json.RawMessage, renames map[string]string) (CommandExecutionLog, error) {
+	var log CommandExecutionLog
+	if len(renames) == 0 {
+		err := json.Unmarshal(raw, &log)
+		return log, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return log, err
+	}
+	migrateFields(fields, renames, "")
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return log, err
+	}
+	err = json.Unmarshal(data, &log)
+	return log, err
+}
+
+// checkFields compares raw object fields with the json tags of t. A missing field of requiredFields
+// is structural, a missing field without omitempty is a warning, as is an unknown field.
+func checkFields(fields map[string]json.RawMessage, t reflect.Type, path string) []schemaIssue {
+	var issues []schemaIssue
+	known := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		known[name] = true
+		raw, ok := fields[name]
+		if !ok {
+			switch {
+			case slices.Contains(requiredFields[t], name):
+				issues = append(issues, schemaIssue{Path: joinPath(path, name), Message: "missing required field", Severity: issueError})
+			case !strings.Contains(opts, "omitempty"):
+				issues = append(issues, schemaIssue{Path: joinPath(path, name), Message: "missing field", Severity: issueWarning})
+			}
+			continue
+		}
+		// Nested objects are checked by the caller with checkObject, arrays of objects are only
+		// checked for shape here and their items by the caller.
+		target := reflect.New(field.Type).Interface()
+		switch {
+		case field.Type.Kind() == reflect.Struct:
+			continue
+		case field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct:
+			target = &[]json.RawMessage{}
+		}
+		if err := json.Unmarshal(raw, target); err != nil {
+			issues = append(issues, schemaIssue{Path: joinPath(path, name), Message: fmt.Sprintf("expected %s: %v", field.Type, err), Severity: issueError})
+		}
+	}
+	for _, name := range sortedKeys(fields) {
+		if !known[name] {
+			issues = append(issues, schemaIssue{Path: joinPath(path, name), Message: "unknown field", Severity: issueWarning})
+		}
+	}
+	return issues
+}
+
+// checkObject decodes raw as an object and checks its fields against t.
+func checkObject(raw json.RawMessage, t reflect.Type, path string) (map[string]json.RawMessage, []schemaIssue) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil || fields == nil {
+		return nil, []schemaIssue{{Path: path, Message: "expected an object", Severity: issueError}}
+	}
+	return fields, checkFields(fields, t, path)
+}
+
+// checkReportMeta validates every top-level field except execution_logs, which is checked while streaming.
+func checkReportMeta(fields map[string]json.RawMessage) []schemaIssue {
+	issues := checkFields(fields, reflect.TypeOf(JSONOutput{}), "")
+	if raw, ok := fields["backup"]; ok {
+		_, backupIssues := checkObject(raw, reflect.TypeOf(JSONBackupPaths{}), "backup")
+		issues = append(issues, backupIssues...)
+	}
+	if raw, ok := fields["results"]; ok {
+		results, resultIssues := checkObject(raw, reflect.TypeOf(JSONResults{}), "results")
+		issues = append(issues, resultIssues...)
+		for _, category := range resultCategories {
+			var items []json.RawMessage
+			if raw, ok := results[category]; !ok || json.Unmarshal(raw, &items) != nil {
+				continue // already reported by checkFields
+			}
+			for i, item := range items {
+				_, itemIssues := checkObject(item, reflect.TypeOf(JSONResultItem{}), fmt.Sprintf("results.%s[%d]", category, i))
+				issues = append(issues, itemIssues...)
+			}
+		}
+	}
+	return issues
+}
+
+// validateReport checks a report file against the JSONOutput schema after applying the
+// migrations for its version. Only a file that cannot be read at all returns an error.
+func validateReport(filePath string) ([]schemaIssue, error) {
+	if _, err := os.Stat(filePath); err != nil {
+		return nil, fmt.Errorf("could not read input file: %w", err)
+	}
+	rf, err := openReport(filePath)
+	if err != nil {
+		return []schemaIssue{{Message: err.Error(), Severity: issueError}}, nil
+	}
+	defer rf.Close()
+	fields := rf.fields
+	version, _ := reportVersion(fields)
+	issues := migrateReport(fields)
+	issues = append(issues, checkReportMeta(fields)...)
+
+	// The same problem in thousands of execution logs is reported once with a count.
+	renames := logRenamesFor(version)
+	counted := make(map[schemaIssue]int)
+	var order []schemaIssue
+	count := func(issue schemaIssue) {
+		if counted[issue] == 0 {
+			order = append(order, issue)
+		}
+		counted[issue]++
+	}
+	_, err = rf.scanExecutionLogs(version, func(raw json.RawMessage, _ int64) error {
+		var logFields map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &logFields); err != nil || logFields == nil {
+			count(schemaIssue{Path: "execution_logs[]", Message: "expected an object", Severity: issueError})
+			return nil
+		}
+		for _, issue := range migrateFields(logFields, renames, "execution_logs[]") {
+			count(issue)
+		}
+		for _, issue := range checkFields(logFields, reflect.TypeOf(CommandExecutionLog{}), "execution_logs[]") {
+			count(issue)
+		}
+		return nil
+	})
+	if err != nil {
+		issues = append(issues, schemaIssue{Path: "execution_logs", Message: err.Error(), Severity: issueError})
+	}
+	for _, issue := range order {
+		if n := counted[issue]; n > 1 {
+			issue.Message = fmt.Sprintf("%s (%d entries)", issue.Message, n)
+		}
+		issues = append(issues, issue)
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Severity > issues[j].Severity
+	})
+	return issues, nil
+}
+
+// validate prints the schema issues of every input report and fails if any of them has structural problems.
+func validate() error {
+	paths, err := resolveReportInputs(*figs.String(argInputFile))
+	if err != nil {
+		return err
+	}
+	var failed []string
+	for _, filePath := range paths {
+		issues, err := validateReport(filePath)
+		if err != nil {
+			return err
+		}
+		structural := 0
+		for _, issue := range issues {
+			if issue.Severity == issueError {
+				structural++
+			}
+			fmt.Printf("%-5s %s\n", issue.Severity, issue)
+		}
+		fmt.Printf("%s: %d issue(s), %d structural\n", filePath, len(issues), structural)
+		if structural > 0 {
+			failed = append(failed, filePath)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed validation: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// String returns the label printed in front of an issue.
+func (s issueSeverity) String() string {
+	switch s {
+	case issueError:
+		return "ERROR"
+	case issueWarning:
+		return "WARN"
+	default:
+		return "NOTE"
+	}
+}
+
+// countIssues returns how many issues have at least the given severity.
+func countIssues(issues []schemaIssue, min issueSeverity) int {
+	n := 0
+	for _, issue := range issues {
+		if issue.Severity >= min {
+			n++
+		}
+	}
+	return n
+}
+
+// String formats the issue as "path: message".
+func (i schemaIssue) String() string {
+	if i.Path == "" {
+		return i.Message
+	}
+	return i.Path + ": " + i.Message
+}
+
+// parseVersion parses "v1.2.3" (the v, patch and any pre-release suffix are optional).
+func parseVersion(version string) ([3]int, bool) {
+	var parts [3]int
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	version, _, _ = strings.Cut(version, "-")
+	fields := strings.Split(version, ".")
+	if len(fields) == 0 || len(fields) > 3 {
+		return parts, false
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
+
+// compareVersions returns -1, 0 or 1 as a is older than, equal to or newer than b.
+// Unparseable versions sort as the oldest.
+func compareVersions(a, b string) int {
+	va, _ := parseVersion(a)
+	vb, _ := parseVersion(b)
+	for i := range va {
+		switch {
+		case va[i] < vb[i]:
+			return -1
+		case va[i] > vb[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
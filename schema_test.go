@@ -0,0 +1,146 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateReport(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		report     string
+		structural []string // issues that fail -validate
+		warnings   []string
+	}{
+		{
+			name:   "optional fields left out",
+			report: `{"state": "s3://b/dev.tfstate", "version": "v1.3.0", "results": {"DANGEROUS": [{"resource": "aws_s3_bucket.b", "kind": "aws_s3_bucket"}]}, "execution_logs": [{"command": "terraform plan"}]}`,
+			warnings: []string{"region: missing field", "results.OK: missing field", "results.DANGEROUS[0].message: missing field",
+				"execution_logs[].exit_code: missing field"},
+		},
+		{
+			name:       "required fields missing",
+			report:     `{"version": "v1.3.0", "results": {"ERROR": [{"resource": "aws_s3_bucket.b"}]}, "execution_logs": [{"stdout": ""}, {"stdout": ""}]}`,
+			structural: []string{"state: missing required field", "results.ERROR[0].kind: missing required field", "execution_logs[].command: missing required field (2 entries)"},
+		},
+		{
+			name:       "no results",
+			report:     `{"state": "s3://b/dev.tfstate", "version": "v1.3.0"}`,
+			structural: []string{"results: missing required field"},
+		},
+		{
+			name:       "wrong types",
+			report:     `{"state": "s", "version": "v1.3.0", "state_version": "7", "results": {"OK": {}}, "backup": [], "execution_logs": [3]}`,
+			structural: []string{"state_version: expected uint64", "results.OK: expected []main.JSONResultItem", "backup: expected an object", "execution_logs[]: expected an object"},
+		},
+		{
+			name:     "unknown fields and no version",
+			report:   `{"state": "s", "results": {}, "extra": 1}`,
+			warnings: []string{"extra: unknown field", "version: missing, assuming the oldest report format"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			issues, err := validateReport(writeRawReport(t, tc.report))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var structural, warnings []string
+			for _, issue := range issues {
+				switch issue.Severity {
+				case issueError:
+					structural = append(structural, issue.String())
+				case issueWarning:
+					warnings = append(warnings, issue.String())
+				}
+			}
+			if len(structural) != len(tc.structural) {
+				t.Errorf("structural issues = %q, want %q", structural, tc.structural)
+			}
+			for _, want := range tc.structural {
+				if !containsPrefix(structural, want) {
+					t.Errorf("structural issues = %q, want %q", structural, want)
+				}
+			}
+			for _, want := range tc.warnings {
+				if !containsPrefix(warnings, want) {
+					t.Errorf("warnings = %q, want %q", warnings, want)
+				}
+			}
+		})
+	}
+}
+
+func containsPrefix(issues []string, prefix string) bool {
+	for _, issue := range issues {
+		if strings.HasPrefix(issue, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestValidateFailsOnlyOnStructuralIssues(t *testing.T) {
+	setFlag(t, argInputFile, writeRawReport(t, `{"state": "s", "version": "v1.3.0", "results": {}, "extra": 1}`))
+	var err error
+	out := captureStdout(t, func() { err = validate() })
+	if err != nil {
+		t.Errorf("validate() = %v for a report with warnings only\n%s", err, out)
+	}
+
+	setFlag(t, argInputFile, writeRawReport(t, `{"version": "v1.3.0", "results": {}}`))
+	out = captureStdout(t, func() { err = validate() })
+	if err == nil || !strings.Contains(out, "1 structural") {
+		t.Errorf("validate() = %v for a report without state\n%s", err, out)
+	}
+}
+
+func TestReportMigrations(t *testing.T) {
+	migrations := reportMigrations
+	t.Cleanup(func() { reportMigrations = migrations })
+	reportMigrations = []reportMigration{{
+		before:     "v2.0.0",
+		renames:    map[string]string{"statefile": "state", "logs": "execution_logs"},
+		logRenames: map[string]string{"address": "terraform_address"},
+	}}
+	legacy := `{"version": "%s", "statefile": "s3://b/dev.tfstate", "results": {}, "logs": [{"command": "terraform plan", "address": "aws_s3_bucket.b"}]}`
+
+	old := writeRawReport(t, strings.Replace(legacy, "%s", "v1.9.0", 1))
+	report, err := loadReportData(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.State != "s3://b/dev.tfstate" || len(report.ExecutionLogs) != 1 || report.ExecutionLogs[0].TerraformAddress != "aws_s3_bucket.b" {
+		t.Errorf("migrated report = %+v", report)
+	}
+	issues, err := validateReport(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var notes []string
+	for _, issue := range issues {
+		if issue.Severity == issueError {
+			t.Errorf("structural issue after the migration: %s", issue)
+		}
+		notes = append(notes, issue.String())
+	}
+	for _, want := range []string{`statefile: legacy field migrated to "state"`, `logs: legacy field migrated to "execution_logs"`, `execution_logs[].address: legacy field migrated to "terraform_address"`} {
+		if !containsPrefix(notes, want) {
+			t.Errorf("notes = %q, want %q", notes, want)
+		}
+	}
+
+	// a report of the version that renamed the fields is not migrated
+	current := writeRawReport(t, strings.Replace(legacy, "%s", "v2.0.0", 1))
+	if issues, err = validateReport(current); err != nil {
+		t.Fatal(err)
+	}
+	var structural []string
+	for _, issue := range issues {
+		if issue.Severity == issueError {
+			structural = append(structural, issue.String())
+		}
+	}
+	if !containsPrefix(structural, "state: missing required field") {
+		t.Errorf("structural issues of a current report with legacy fields = %q", structural)
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/andreimerlescu/prettyboy/prettyboy"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+// compileSearch turns the search query into a regular expression. An all-lowercase query matches
+// case-insensitively, and a query that is not a valid regular expression is searched literally.
+func compileSearch(query string) (*regexp.Regexp, bool) {
+	prefix := ""
+	if strings.ToLower(query) == query {
+		prefix = "(?i)"
+	}
+	if re, err := regexp.Compile(prefix + query); err == nil {
+		return re, true
+	}
+	return regexp.MustCompile(prefix + regexp.QuoteMeta(query)), false
+}
+
+// displayedLog returns an execution log the way the detail view shows it: the command formatted,
+// stdout and stderr without the [command] lines and cut at ::debug::. The match counts of the list
+// and n/N in the detail view both search this, so they agree.
+func displayedLog(log CommandExecutionLog) CommandExecutionLog {
+	log.Command = prettyboy.Command(log.Command)
+	log.Stdout = truncateLogAtMarker(filterLinesWithPrefix(log.Stdout, "[command]"), "::debug::")
+	log.Stderr = truncateLogAtMarker(filterLinesWithPrefix(log.Stderr, "[command]"), "::debug::")
+	return log
+}
+
+// displayedLogAt returns the i-th execution log of the active report as displayedLog does. The
+// logs are formatted once per load of the report, not for every search and streamed batch.
+func (m model) displayedLogAt(i int) CommandExecutionLog {
+	ws := m.active
+	for len(ws.displayed) <= i {
+		ws.displayed = append(ws.displayed, displayedLog(ws.report.ExecutionLogs[len(ws.displayed)]))
+	}
+	return ws.displayed[i]
+}
+
+// countLogMatches returns how often re matches the fields of an execution log that the detail
+// view shows. The log is one displayedLog returned.
+func countLogMatches(log CommandExecutionLog, re *regexp.Regexp) int {
+	n := 0
+	for _, field := range []string{log.Command, log.Stdout, log.Stderr, log.Error, log.TerraformAddress} {
+		if field != "" {
+			n += len(re.FindAllStringIndex(field, -1))
+		}
+	}
+	return n
+}
+
+// capturesInput reports whether keys should go to a text input or list filter instead of being
+// treated as global shortcuts.
+func (m model) capturesInput() bool {
+	switch m.state {
+	case viewSearch, viewConfigEdit, viewAnnotate:
+		return true
+	}
+	return m.mainList.FilterState() == list.Filtering ||
+		m.environmentList.FilterState() == list.Filtering ||
+		m.logTreeList.FilterState() == list.Filtering ||
+		m.linkedLogList.FilterState() == list.Filtering
+}
+
+func (m model) updateSearchView(msg tea.Msg) (model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, m.keys.Cancel):
+			m.state = m.popView()
+			return m, nil
+		case key.Matches(msg, m.keys.Submit):
+			query := m.textInput.Value()
+			m.state = m.popView()
+			if query == "" {
+				m.clearSearch()
+				return m, nil
+			}
+			re, valid := compileSearch(query)
+			m.search.query, m.search.re = query, re
+			m.loadMainList()
+			m.mainList.Select(0)
+			if !valid {
+				m.setNotification(fmt.Sprintf("/%s/ is not a valid regular expression, searching for it literally", query), true)
+				return m, m.clearNotificationAfter(3 * time.Second)
+			}
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+// clearSearch removes the search and shows every execution log again.
+func (m *model) clearSearch() {
+	m.search = searchState{}
+	m.loadMainList()
+}
+
+// writeSearchable writes the body of a field to the detail view with the matches highlighted, and
+// records the line of every match for n/N. Headings are written around it, so they never match.
+// A width of 0 leaves the body unwrapped.
+func (m *model) writeSearchable(b *strings.Builder, body string, width int) {
+	if m.search.re == nil {
+		if width > 0 {
+			body = wordwrap.String(body, width)
+		}
+		b.WriteString(body)
+		return
+	}
+	// Every match starts with a zero-width marker and counts once, on the line it starts on, even
+	// when it spans a wrapped or a real line break. So n/N finds as many matches as countLogMatches
+	// counted over the whole field for the list.
+	text := m.search.re.ReplaceAllStringFunc(strings.ReplaceAll(body, matchMarker, ""), func(match string) string {
+		return matchMarker + matchStyle.Render(match)
+	})
+	if width > 0 {
+		text = wordwrap.String(text, width)
+	}
+	first := strings.Count(b.String(), "\n")
+	for i, line := range strings.Split(text, "\n") {
+		for n := strings.Count(line, matchMarker); n > 0; n-- {
+			m.search.hits = append(m.search.hits, first+i)
+		}
+	}
+	b.WriteString(strings.ReplaceAll(text, matchMarker, ""))
+}
+
+// jumpToMatch scrolls the detail view to the line of the next (or previous) match, wrapping around.
+func (m *model) jumpToMatch(forward bool) {
+	n := len(m.search.hits)
+	if n == 0 {
+		m.setNotification(fmt.Sprintf("No matches for /%s/ in this log", m.search.query), true)
+		return
+	}
+	switch {
+	case m.search.current < 0 && forward:
+		m.search.current = 0
+	case m.search.current < 0:
+		m.search.current = n - 1
+	case forward:
+		m.search.current = (m.search.current + 1) % n
+	default:
+		m.search.current = (m.search.current - 1 + n) % n
+	}
+	m.viewPort.SetYOffset(m.search.hits[m.search.current])
+}
+
+// matchPosition describes the current match for the help line, e.g. "2/7".
+func (m model) matchPosition() string {
+	if m.search.current < 0 {
+		return fmt.Sprintf("%d matches", len(m.search.hits))
+	}
+	return fmt.Sprintf("%d/%d", m.search.current+1, len(m.search.hits))
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestDetailMatchesAgreeWithTheListCount(t *testing.T) {
+	m := newNavModel(t, "dev")
+	m.search.query = `alpha\sbeta`
+	m.search.re, _ = compileSearch(m.search.query)
+	width := m.viewPort.Width
+
+	// Slide the match across the wrap width, and across a real line break at the end.
+	for pad := 0; pad <= width; pad++ {
+		log := CommandExecutionLog{
+			Command: "terraform plan",
+			Stdout:  strings.Repeat("x", pad) + " alpha beta gamma alpha beta\nalpha\nbeta",
+		}
+		want := countLogMatches(displayedLog(log), m.search.re)
+		m.activeExecutionLog = log
+		m.renderExecutionLogDetail()
+		if len(m.search.hits) != want {
+			t.Fatalf("pad %d: the detail view has %d matches, the list counted %d", pad, len(m.search.hits), want)
+		}
+		lines := strings.Split(ansi.Strip(m.viewPort.View()), "\n")
+		for _, hit := range m.search.hits {
+			if hit < len(lines) && !strings.Contains(lines[hit], "alpha") {
+				t.Fatalf("pad %d: match on line %d %q", pad, hit, lines[hit])
+			}
+		}
+		if strings.Contains(m.viewPort.View(), matchMarker) {
+			t.Fatalf("pad %d: the detail view shows the match marker", pad)
+		}
+	}
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os/exec"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed serve.html
+var serveUI []byte
+
+// newServer loads every -input report in full for -serve.
+func newServer(input, token string) (*server, error) {
+	paths, err := resolveEnvironmentReports(input)
+	if err != nil {
+		return nil, err
+	}
+	s := &server{token: token, reports: make(map[string]*serveReport)}
+	for _, path := range paths {
+		report, err := loadReportData(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		env := reportEnvironment(path)
+		s.envs = append(s.envs, env)
+		s.reports[env] = &serveReport{env: env, path: path, report: report}
+	}
+	return s, nil
+}
+
+// routes registers the API and the web UI. Everything is read-only, except /api/exec with a token.
+func (s *server) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(serveUI)
+	})
+	mux.HandleFunc("GET /api/reports", s.handleReports)
+	mux.HandleFunc("GET /api/meta", s.handleMeta)
+	mux.HandleFunc("GET /api/results", s.handleResults)
+	mux.HandleFunc("GET /api/logs", s.handleLogs)
+	mux.HandleFunc("GET /api/backups", s.handleBackups)
+	mux.HandleFunc("GET /api/errors", s.handleErrors)
+	mux.HandleFunc("POST /api/exec", s.handleExec)
+	return mux
+}
+
+// serve runs the HTTP server of -serve until it fails.
+func serve(addr string) error {
+	s, err := newServer(*figs.String(argInputFile), *figs.String(argServeToken))
+	if err != nil {
+		return err
+	}
+	execution := "disabled"
+	if s.token != "" {
+		execution = "enabled with the -" + argServeToken
+	}
+	log.Printf("Serving %s on %s, command execution %s", strings.Join(s.envs, ", "), addr, execution)
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return srv.ListenAndServe()
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// lookup returns the report an ?env= names, or the first one when there is no env.
+func (s *server) lookup(r *http.Request) (*serveReport, error) {
+	env := r.URL.Query().Get("env")
+	if env == "" {
+		env = s.envs[0]
+	}
+	sr, ok := s.reports[env]
+	if !ok {
+		return nil, fmt.Errorf("unknown env %q, expected one of %s", env, strings.Join(s.envs, ", "))
+	}
+	return sr, nil
+}
+
+func (s *server) handleReports(w http.ResponseWriter, _ *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	reports := make([]serveReportInfo, 0, len(s.envs))
+	for _, env := range s.envs {
+		sr := s.reports[env]
+		reports = append(reports, serveReportInfo{Env: env, Path: sr.path, State: sr.report.State, Region: sr.report.Region})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"reports": reports, "exec": s.token != ""})
+}
+
+func (s *server) handleMeta(w http.ResponseWriter, r *http.Request) {
+	sr, err := s.lookup(r)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	report := sr.report
+	meta := serveMeta{
+		Env:              sr.env,
+		Path:             sr.path,
+		State:            report.State,
+		StateChecksum:    report.StateChecksum,
+		StateVersion:     report.StateVersion,
+		Region:           report.Region,
+		TFVersion:        report.TFVersion,
+		Version:          report.Version,
+		Concurrency:      report.Concurrency,
+		Arguments:        report.Arguments,
+		ApplicationError: report.ApplicationError,
+		Logs:             len(report.ExecutionLogs),
+		Counts:           make(map[string]int),
+		Exec:             s.token != "",
+	}
+	for _, log := range report.ExecutionLogs {
+		if log.ExitCode != 0 {
+			meta.FailedLogs++
+		}
+	}
+	for _, cat := range resultCategories {
+		results, suppressed := visibleResults(report, cat, false)
+		meta.Counts[cat] = len(results)
+		meta.Suppressed += suppressed
+	}
+	writeJSON(w, http.StatusOK, meta)
+}
+
+func (s *server) handleResults(w http.ResponseWriter, r *http.Request) {
+	sr, err := s.lookup(r)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	q := r.URL.Query()
+	categories := resultCategories
+	if cat := q.Get("category"); cat != "" {
+		if !slices.Contains(resultCategories, cat) {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("unknown category %q", cat))
+			return
+		}
+		categories = []string{cat}
+	}
+	query := strings.ToLower(q.Get("q"))
+	all := q.Get("suppressed") == "1"
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	byCategory := make(map[string][]serveResult)
+	for _, cat := range categories {
+		results, _ := visibleResults(sr.report, cat, all)
+		items := make([]serveResult, 0, len(results))
+		for _, item := range results {
+			if query != "" && !strings.Contains(strings.ToLower(item.Resource+"\x00"+item.Kind+"\x00"+item.Message), query) {
+				continue
+			}
+			res := serveResult{JSONResultItem: item}
+			if e, ok := knownFindings.suppression(cat, item); ok {
+				res.Baseline = orDash(e.Reason)
+			}
+			res.Planned, res.Conflict = plannedResult(cat, item)
+			items = append(items, res)
+		}
+		byCategory[cat] = items
+	}
+	writeJSON(w, http.StatusOK, byCategory)
+}
+
+func (s *server) handleLogs(w http.ResponseWriter, r *http.Request) {
+	sr, err := s.lookup(r)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	q := r.URL.Query()
+	offset, err := queryInt(q.Get("offset"), 0, 0, -1)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("offset: %w", err))
+		return
+	}
+	limit, err := queryInt(q.Get("limit"), serveDefaultLimit, 1, serveMaxLimit)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("limit: %w", err))
+		return
+	}
+	query := strings.ToLower(q.Get("q"))
+	failed := q.Get("failed") == "1"
+	source, address := q.Get("source"), q.Get("address")
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var matched []serveLog
+	for i, log := range sr.report.ExecutionLogs {
+		switch {
+		case failed && log.ExitCode == 0,
+			source != "" && log.Source != source,
+			address != "" && !logTouches(log, address),
+			query != "" && !strings.Contains(strings.ToLower(strings.Join([]string{log.Command, log.Stdout, log.Stderr, log.Error, log.TerraformAddress}, "\x00")), query):
+			continue
+		}
+		matched = append(matched, serveLog{Index: i, CommandExecutionLog: log})
+	}
+	page := serveLogPage{Total: len(matched), Offset: offset, Limit: limit, Logs: []serveLog{}}
+	if offset < len(matched) {
+		page.Logs = matched[offset:min(offset+limit, len(matched))]
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *server) handleBackups(w http.ResponseWriter, r *http.Request) {
+	sr, err := s.lookup(r)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	s.mu.RLock()
+	backup := sr.report.Backup
+	s.mu.RUnlock()
+	writeJSON(w, http.StatusOK, backup)
+}
+
+func (s *server) handleErrors(w http.ResponseWriter, r *http.Request) {
+	sr, err := s.lookup(r)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	s.mu.RLock()
+	counts := aggregateErrors(sr.report)
+	s.mu.RUnlock()
+	summary := []ErrorCount{}
+	counts.Range(func(_, v interface{}) bool {
+		summary = append(summary, *v.(*ErrorCount))
+		return true
+	})
+	sort.Slice(summary, func(i, j int) bool {
+		if summary[i].Count != summary[j].Count {
+			return summary[i].Count > summary[j].Count
+		}
+		return summary[i].Error < summary[j].Error
+	})
+	writeJSON(w, http.StatusOK, summary)
+}
+
+// handleExec runs the suggested command of a result, like X in the TUI. It only exists with
+// -serve-token, only runs commands the report suggests, checks the region of the -profile and
+// runs one command at a time.
+func (s *server) handleExec(w http.ResponseWriter, r *http.Request) {
+	if s.token == "" {
+		writeError(w, http.StatusForbidden, fmt.Errorf("command execution is disabled, start with -%s to enable it", argServeToken))
+		return
+	}
+	given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(s.token)) != 1 {
+		writeError(w, http.StatusUnauthorized, errors.New("invalid token, expected Authorization: Bearer <token>"))
+		return
+	}
+	sr, err := s.lookup(r)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	var req struct {
+		Category string `json:"category"`
+		Resource string `json:"resource"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var command string
+	for _, item := range sr.report.Results.GetCategory(req.Category) {
+		if item.Resource == req.Resource && item.Command != "" {
+			command = item.Command
+			break
+		}
+	}
+	if command == "" {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no suggested command for %s in %s", req.Resource, req.Category))
+		return
+	}
+	p := profiles[*figs.String(argProfile)]
+	if err := p.checkRegion(sr.report); err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	// every command runs against the same state, a second one waits for nothing and is refused
+	if !s.execMu.TryLock() {
+		writeError(w, http.StatusConflict, errors.New("another command is running, try again when it is done"))
+		return
+	}
+	defer s.execMu.Unlock()
+
+	out := execCommand(command, p)().(commandOutputMsg)
+	newLog := CommandExecutionLog{
+		TerraformAddress: req.Resource,
+		Command:          command,
+		Stdout:           out.stdout,
+		Stderr:           out.stderr,
+		Source:           "HTTP",
+	}
+	if out.err != nil {
+		newLog.Error = out.err.Error()
+		var exitErr *exec.ExitError
+		if errors.As(out.err, &exitErr) {
+			newLog.ExitCode = exitErr.ExitCode()
+		}
+	}
+	newLog = secrets.redactLog(newLog)
+	s.mu.Lock()
+	sr.report.ExecutionLogs = append(sr.report.ExecutionLogs, newLog)
+	s.mu.Unlock()
+	log.Printf("exec %s %s: exit %d", sr.env, command, newLog.ExitCode)
+	writeJSON(w, http.StatusOK, newLog)
+}
+
+// queryInt parses a paging parameter; max < 0 means unbounded.
+func queryInt(v string, def, lo, hi int) (int, error) {
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, err
+	}
+	if n < lo || (hi >= 0 && n > hi) {
+		if hi < 0 {
+			return 0, fmt.Errorf("must be at least %d", lo)
+		}
+		return 0, fmt.Errorf("must be between %d and %d", lo, hi)
+	}
+	return n, nil
+}
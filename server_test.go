@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewServerRejectsDuplicateEnvironments(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"us-east-1", "us-west-2"} {
+		if err := os.Mkdir(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatal(err)
+		}
+		writeReport(t, filepath.Join(dir, sub), "prod", JSONOutput{State: sub})
+	}
+	_, err := newServer(filepath.Join(dir, "*", "report.prod.json"), "")
+	if err == nil || !strings.Contains(err.Error(), "both the prod environment") {
+		t.Fatalf("newServer error = %v, want the duplicate prod environment", err)
+	}
+}
+
+func TestServerBackups(t *testing.T) {
+	dir := t.TempDir()
+	writeReport(t, dir, "dev", JSONOutput{State: "dev", Backup: JSONBackupPaths{NewPath: "/backups/dev.tfstate"}})
+	s, err := newServer(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(s.routes())
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL + "/api/backups?env=dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	var backup JSONBackupPaths
+	if err := json.NewDecoder(res.Body).Decode(&backup); err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusOK || backup.NewPath != "/backups/dev.tfstate" {
+		t.Fatalf("GET /api/backups = %s %+v", res.Status, backup)
+	}
+}
+
+// postExec posts the exec request of the aws_s3_bucket.b result with the Authorization header. It
+// runs in goroutines as well, so it fails the test with Errorf and a zero status.
+func postExec(t *testing.T, url, authorization string) (int, CommandExecutionLog) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url+"/api/exec?env=dev", strings.NewReader(`{"category": "POTENTIAL_IMPORT", "resource": "aws_s3_bucket.b"}`))
+	if err != nil {
+		t.Error(err)
+		return 0, CommandExecutionLog{}
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Error(err)
+		return 0, CommandExecutionLog{}
+	}
+	defer res.Body.Close()
+	var out CommandExecutionLog
+	_ = json.NewDecoder(res.Body).Decode(&out)
+	return res.StatusCode, out
+}
+
+func TestServerExec(t *testing.T) {
+	t.Setenv(envTfDir, "")
+	t.Setenv(envTfState, "")
+	setFlag(t, argProfile, "")
+	dir := t.TempDir()
+	started, release := filepath.Join(dir, "started"), filepath.Join(dir, "release")
+	// the command blocks until the test releases it, to send a second request while it runs
+	command := fmt.Sprintf("touch '%s'; while [ ! -e '%s' ]; do sleep 0.01; done; echo imported", started, release)
+	writeReport(t, dir, "dev", JSONOutput{State: "dev", Results: JSONResults{
+		PotentialImportResults: []JSONResultItem{{Resource: "aws_s3_bucket.b", Kind: "aws_s3_bucket", Command: command}},
+	}})
+
+	open, err := newServer(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(open.routes())
+	defer srv.Close()
+	if status, _ := postExec(t, srv.URL, "Bearer s3cret"); status != http.StatusForbidden {
+		t.Errorf("exec without -serve-token = %d, want 403", status)
+	}
+
+	s, err := newServer(dir, "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv = httptest.NewServer(s.routes())
+	defer srv.Close()
+	for _, authorization := range []string{"", "Bearer wrong", "s3cret", "bearer s3cret", "Basic s3cret"} {
+		if status, _ := postExec(t, srv.URL, authorization); status != http.StatusUnauthorized {
+			t.Errorf("exec with Authorization %q = %d, want 401", authorization, status)
+		}
+	}
+	if _, err := os.Stat(started); err == nil {
+		t.Fatal("an unauthorized request ran the command")
+	}
+
+	type result struct {
+		status int
+		log    CommandExecutionLog
+	}
+	first := make(chan result)
+	go func() {
+		status, log := postExec(t, srv.URL, "Bearer s3cret")
+		first <- result{status, log}
+	}()
+	for deadline := time.Now().Add(10 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if _, err := os.Stat(started); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the command did not start")
+		}
+	}
+	if status, _ := postExec(t, srv.URL, "Bearer s3cret"); status != http.StatusConflict {
+		t.Errorf("exec while a command runs = %d, want 409", status)
+	}
+	if err := os.WriteFile(release, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	got := <-first
+	if got.status != http.StatusOK || got.log.Stdout != "imported\n" || got.log.Source != "HTTP" {
+		t.Fatalf("exec = %d %+v", got.status, got.log)
+	}
+	if logs := s.reports["dev"].report.ExecutionLogs; len(logs) != 1 || logs[0].Command != command {
+		t.Errorf("execution logs = %+v, want the command that ran", logs)
+	}
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openReport opens a report and reads its top-level fields. The execution logs are framed but not
+// decoded, and where they start is recorded, so they can be decoded from the open file afterwards
+// without reading it from the top again. The caller closes the report.
+func openReport(filePath string) (*reportFile, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("input file not found: %s", filePath)
+		}
+		return nil, fmt.Errorf("could not read input file: %w", err)
+	}
+	rf := &reportFile{File: f, fields: make(map[string]json.RawMessage), logsAt: make(map[string]int64)}
+	if err := rf.readFields(); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("could not parse JSON from input file: %w", err)
+	}
+	return rf, nil
+}
+
+// readFields reads the top-level fields of the report. Any key that can hold the execution logs (in
+// the current or a legacy format) is skipped byte by byte and recorded as an empty array.
+func (rf *reportFile) readFields() error {
+	s := &reportScanner{r: bufio.NewReader(rf.File)}
+	if c, err := s.next(); err != nil || c != '{' {
+		return s.unexpected(c, err, "'{'")
+	}
+	logsKeys := logsKeyFor(oldestReportVersion)
+	for first := true; ; first = false {
+		c, err := s.next()
+		switch {
+		case err != nil:
+			return s.unexpected(c, err, "a key")
+		case c == '}':
+			return nil
+		case !first && c != ',':
+			return s.unexpected(c, nil, "',' or '}'")
+		case !first:
+			if c, err = s.next(); err != nil {
+				return s.unexpected(c, err, "a key")
+			}
+		}
+		if c != '"' {
+			return s.unexpected(c, nil, "a key")
+		}
+		var raw bytes.Buffer
+		if err := s.value(c, &raw); err != nil {
+			return err
+		}
+		var key string
+		if err := json.Unmarshal(raw.Bytes(), &key); err != nil {
+			return fmt.Errorf("offset %d: %w", s.offset, err)
+		}
+		if c, err = s.next(); err != nil || c != ':' {
+			return s.unexpected(c, err, "':'")
+		}
+		if c, err = s.next(); err != nil {
+			return s.unexpected(c, err, "a value")
+		}
+		if logsKeys[key] {
+			rf.logsAt[key] = s.offset - 1
+			if err := s.value(c, nil); err != nil {
+				return err
+			}
+			rf.fields[key] = json.RawMessage("[]")
+			continue
+		}
+		raw.Reset()
+		if err := s.value(c, &raw); err != nil {
+			return err
+		}
+		rf.fields[key] = raw.Bytes()
+	}
+}
+
+// next returns the next byte that is not whitespace.
+func (s *reportScanner) next() (byte, error) {
+	for {
+		c, err := s.r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		s.offset++
+		switch c {
+		case ' ', '\t', '\n', '\r':
+			continue
+		}
+		return c, nil
+	}
+}
+
+// value frames the JSON value that starts with c, copying its bytes to keep unless keep is nil.
+// Strings and nesting are tracked so the value ends where it should; whether its content is valid
+// JSON is left to whoever decodes it.
+func (s *reportScanner) value(c byte, keep *bytes.Buffer) error {
+	write := func(c byte) {
+		if keep != nil {
+			keep.WriteByte(c)
+		}
+	}
+	depth, quoted, escaped := 0, false, false
+	for {
+		write(c)
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && c == '\\':
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			depth--
+		}
+		if depth == 0 && !quoted && c != '}' && c != ']' && c != '"' {
+			// a number, true, false or null ends before the next delimiter or whitespace
+			next, err := s.r.Peek(1)
+			if err != nil || bytes.ContainsAny(next, ",}] \t\n\r") {
+				return nil
+			}
+		} else if depth == 0 && !quoted {
+			return nil
+		}
+		var err error
+		if c, err = s.r.ReadByte(); err != nil {
+			return s.unexpected(0, err, "the end of the value")
+		}
+		s.offset++
+	}
+}
+
+// unexpected describes a byte the report should not have at this point.
+func (s *reportScanner) unexpected(c byte, err error, want string) error {
+	switch {
+	case err == io.EOF:
+		return fmt.Errorf("offset %d: unexpected end of file, expected %s", s.offset, want)
+	case err != nil:
+		return err
+	}
+	return fmt.Errorf("offset %d: unexpected %q, expected %s", s.offset, c, want)
+}
+
+// meta decodes every top-level field of the report except execution_logs, so the TUI can start
+// before the (potentially huge) logs are read. The fields are migrated to the current format first;
+// the returned issues describe what was migrated and anything that does not match the schema.
+func (rf *reportFile) meta() (*JSONOutput, []schemaIssue, error) {
+	issues := migrateReport(rf.fields)
+	issues = append(issues, checkReportMeta(rf.fields)...)
+
+	data, err := json.Marshal(rf.fields)
+	if err != nil {
+		return nil, issues, fmt.Errorf("could not re-encode report metadata: %w", err)
+	}
+	var report JSONOutput
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, issues, fmt.Errorf("could not parse JSON from input file: %w", err)
+	}
+	report.ExecutionLogs = nil
+	report.ApplicationError = secrets.redact(report.ApplicationError)
+	secrets.redactResults(&report.Results)
+	return &report, issues, nil
+}
+
+// loadReportMeta opens a report only for its metadata, see meta.
+func loadReportMeta(filePath string) (*JSONOutput, []schemaIssue, error) {
+	rf, err := openReport(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rf.Close()
+	return rf.meta()
+}
+
+// scanExecutionLogs calls fn with every raw execution log of the report, in order, along with
+// the number of bytes of the file consumed so far. It returns the size of the file.
+func (rf *reportFile) scanExecutionLogs(version string, fn func(raw json.RawMessage, read int64) error) (int64, error) {
+	var total int64
+	if info, err := rf.Stat(); err == nil {
+		total = info.Size()
+	}
+
+	// The first key in the file that holds the logs for this version wins.
+	key, at := "", int64(-1)
+	for k := range logsKeyFor(version) {
+		if offset, ok := rf.logsAt[k]; ok && (at < 0 || offset < at) {
+			key, at = k, offset
+		}
+	}
+	if at < 0 {
+		return total, nil
+	}
+	if _, err := rf.Seek(at, io.SeekStart); err != nil {
+		return total, err
+	}
+	dec := json.NewDecoder(rf.File)
+	tok, err := dec.Token()
+	if err != nil {
+		return total, err
+	}
+	if tok == nil { // "execution_logs": null
+		return total, nil
+	}
+	if tok != json.Delim('[') {
+		return total, fmt.Errorf("%s: expected array, got %v", key, tok)
+	}
+	for i := 0; dec.More(); i++ {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return total, fmt.Errorf("%s[%d]: %w", key, i, err)
+		}
+		if err := fn(raw, at+dec.InputOffset()); err != nil {
+			return total, fmt.Errorf("%s[%d]: %w", key, i, err)
+		}
+	}
+	return total, nil
+}
+
+// decodeExecutionLogs calls fn with every execution log of the report, migrated to the current
+// format and redacted.
+func (rf *reportFile) decodeExecutionLogs(version string, fn func(log CommandExecutionLog, read int64)) (int64, error) {
+	renames := logRenamesFor(version)
+	return rf.scanExecutionLogs(version, func(raw json.RawMessage, read int64) error {
+		log, err := decodeExecutionLog(raw, renames)
+		if err != nil {
+			return err
+		}
+		fn(secrets.redactLog(log), read)
+		return nil
+	})
+}
+
+// streamExecutionLogs decodes the execution logs of filePath in the background and delivers
+// them in batches of logBatchSize. The channel is closed after the final batch, which has done
+// set (and err set when decoding failed part way through).
+func streamExecutionLogs(report *JSONOutput, filePath string) <-chan logBatchMsg {
+	ch := make(chan logBatchMsg, 1)
+	go func() {
+		defer close(ch)
+		rf, err := openReport(filePath)
+		if err != nil {
+			ch <- logBatchMsg{report: report, stream: ch, done: true, err: err}
+			return
+		}
+		defer rf.Close()
+		var size int64
+		if info, err := rf.Stat(); err == nil {
+			size = info.Size()
+		}
+		batch := make([]CommandExecutionLog, 0, logBatchSize)
+		total, err := rf.decodeExecutionLogs(report.Version, func(log CommandExecutionLog, read int64) {
+			batch = append(batch, log)
+			if len(batch) == logBatchSize {
+				ch <- logBatchMsg{report: report, stream: ch, logs: batch, read: read, total: size}
+				batch = make([]CommandExecutionLog, 0, logBatchSize)
+			}
+		})
+		ch <- logBatchMsg{report: report, stream: ch, logs: batch, read: total, total: total, done: true, err: err}
+	}()
+	return ch
+}
+
+// waitForLogBatch blocks until the next batch of streamed execution logs is available.
+func waitForLogBatch(ch <-chan logBatchMsg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeRawReport writes a report as given, for fields that JSONOutput cannot express.
+func writeRawReport(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "report.dev.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadReportDataReadsTheLogsInPlace(t *testing.T) {
+	path := writeRawReport(t, `{
+  "execution_logs": [
+    {"command": "terraform import 'aws_s3_bucket.b[\"}]\"]' b", "exit_code": 0, "stdout": "{[\\\"", "start_time": "2024-01-01T00:00:00Z", "end_time": "2024-01-01T00:00:01Z"},
+    {"command": "terraform state rm a", "exit_code": 1, "error": "locked"}
+  ],
+  "state": "s3://b/dev.tfstate", "state_version": 7, "concurrency": 4, "backup": {"state": "b"},
+  "results": {"OK": [{"resource": "aws_s3_bucket.b", "kind": "aws_s3_bucket"}]},
+  "arguments": ["-x"], "version": "v1.3.0"}`)
+
+	rf, err := openReport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at, ok := rf.logsAt["execution_logs"]
+	if _, seekErr := rf.Seek(at, 0); !ok || seekErr != nil {
+		t.Fatalf("logsAt = %v, %v", rf.logsAt, seekErr)
+	}
+	first := make([]byte, 1)
+	if _, err := rf.Read(first); err != nil || first[0] != '[' {
+		t.Errorf("the logs offset points at %q, want '['", first)
+	}
+	rf.Close()
+
+	report, err := loadReportData(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.State != "s3://b/dev.tfstate" || report.StateVersion != 7 || report.Concurrency != 4 || report.Version != "v1.3.0" || len(report.Results.OkResults) != 1 {
+		t.Errorf("metadata = %+v", report)
+	}
+	if len(report.ExecutionLogs) != 2 || report.ExecutionLogs[0].Command != `terraform import 'aws_s3_bucket.b["}]"]' b` ||
+		report.ExecutionLogs[0].Stdout != `{[\"` || report.ExecutionLogs[1].Error != "locked" {
+		t.Errorf("execution logs = %+v", report.ExecutionLogs)
+	}
+}
+
+func TestLoadReportDataErrors(t *testing.T) {
+	for _, tc := range []struct {
+		report string
+		want   string
+	}{
+		{`{"state": "s", "execution_logs": null, "results": {}}`, ""},
+		{`{"state": "s", "results": {}}`, ""},
+		{`{"state": "s", "execution_logs": [{"command": "a"}`, "unexpected end of file"},
+		{`{"state" "s"}`, "expected ':'"},
+		{`{"state": "s",}`, "expected a key"},
+		{`["state"]`, "expected '{'"},
+		{`{"state": "s", "execution_logs": {"command": "a"}, "results": {}}`, "expected array"},
+		{`{"state": "s", "execution_logs": [{"command": 1}], "results": {}}`, "execution_logs[0]"},
+	} {
+		_, err := loadReportData(writeRawReport(t, tc.report))
+		switch {
+		case tc.want == "" && err != nil:
+			t.Errorf("loadReportData(%s) = %v", tc.report, err)
+		case tc.want != "" && (err == nil || !strings.Contains(err.Error(), tc.want)):
+			t.Errorf("loadReportData(%s) = %v, want %q", tc.report, err, tc.want)
+		}
+	}
+}
+
+func TestStreamedBatchesMatchARebuild(t *testing.T) {
+	m := newNavModel(t, "dev")
+	m.logSort = sortByExitCode
+	m.search.query = "b[1-9]"
+	m.search.re, _ = compileSearch(m.search.query)
+	m.active.loading = true
+	m.loadMainList()
+	m.loadLogTree()
+	m.setTreeExpanded(true)
+
+	for batch := 0; batch < 3; batch++ {
+		var logs []CommandExecutionLog
+		for i := 0; i < 7; i++ {
+			logs = append(logs, CommandExecutionLog{
+				TerraformAddress: fmt.Sprintf("module.m%d.aws_s3_bucket.b%d", i%2, (batch+i)%4),
+				Command:          fmt.Sprintf("terraform import b%d-%d", batch, i),
+				ExitCode:         (batch + i) % 3,
+			})
+		}
+		m = update(t, m, logBatchMsg{report: m.report, logs: logs, done: batch == 2})
+	}
+
+	streamed := m.mainList.Items()
+	streamedTitle := m.mainList.Title
+	var streamedTree []string
+	for _, item := range m.logTreeList.Items() {
+		streamedTree = append(streamedTree, item.(logTreeItem).render())
+	}
+
+	m.loadMainList()
+	m.loadLogTree()
+	rebuilt := m.mainList.Items()
+	if len(rebuilt) < 10 || len(rebuilt) == len(m.report.ExecutionLogs) {
+		t.Fatalf("the search shows %d of %d logs, the test needs it to hide some", len(rebuilt), len(m.report.ExecutionLogs))
+	}
+	if len(streamed) != len(rebuilt) || streamedTitle != m.mainList.Title {
+		t.Fatalf("streamed %d items %q, rebuilt %d items %q", len(streamed), streamedTitle, len(rebuilt), m.mainList.Title)
+	}
+	for i := range rebuilt {
+		if streamed[i].(mainItem).log.Command != rebuilt[i].(mainItem).log.Command {
+			t.Errorf("item %d = %q, want %q", i, streamed[i].(mainItem).log.Command, rebuilt[i].(mainItem).log.Command)
+		}
+	}
+	var rebuiltTree []string
+	for _, item := range m.logTreeList.Items() {
+		rebuiltTree = append(rebuiltTree, item.(logTreeItem).render())
+	}
+	if strings.Join(streamedTree, "\n") != strings.Join(rebuiltTree, "\n") {
+		t.Errorf("streamed tree:\n%s\nrebuilt tree:\n%s", strings.Join(streamedTree, "\n"), strings.Join(rebuiltTree, "\n"))
+	}
+}
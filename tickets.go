@@ -0,0 +1,318 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ticketKey identifies the ticket of a result. It leaves out the category, so a result that
+// moves from ERROR to DANGEROUS keeps its ticket, and it is the same on every run.
+func ticketKey(env string, item JSONResultItem) string {
+	sum := sha256.Sum256([]byte(env + "\x00" + resultKey(item)))
+	return "tfrr-" + hex.EncodeToString(sum[:6])
+}
+
+// collectTickets builds a ticket for every ERROR and DANGEROUS result of the -input reports that
+// the -baseline does not accept.
+func collectTickets(input, format string) ([]ticket, error) {
+	paths, err := resolveEnvironmentReports(input)
+	if err != nil {
+		return nil, err
+	}
+	var tickets []ticket
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		report, err := loadReportData(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		env := reportEnvironment(path)
+		for _, cat := range ticketCategories {
+			results, _ := visibleResults(report, cat, false)
+			for _, item := range results {
+				key := ticketKey(env, item)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				tickets = append(tickets, ticket{
+					Key:      key,
+					Env:      env,
+					Category: cat,
+					Title:    fmt.Sprintf("[%s] %s: %s", cat, env, item.Resource),
+					Body:     renderTicketBody(format, path, env, cat, key, item),
+					Labels:   []string{appName, strings.ToLower(cat), key},
+				})
+			}
+		}
+	}
+	return tickets, nil
+}
+
+// renderTicketBody describes a result in the markup of the tracker: Markdown for GitHub, wiki
+// markup for Jira. The dedup key is repeated at the end, so a search finds the ticket again.
+func renderTicketBody(format, path, env, category, key string, item JSONResultItem) string {
+	var b strings.Builder
+	field := func(name, value string) {
+		if format == ticketsJira {
+			b.WriteString(fmt.Sprintf("*%s:* %s\n", name, orDash(value)))
+		} else {
+			b.WriteString(fmt.Sprintf("**%s:** %s\n", name, orDash(value)))
+		}
+	}
+	code := func(value string) string {
+		if format == ticketsJira {
+			return "{{" + value + "}}"
+		}
+		return "`" + value + "`"
+	}
+	field("Category", category)
+	field("Environment", env)
+	field("Resource", code(item.Resource))
+	field("Kind", item.Kind)
+	field("Terraform ID", item.TFID)
+	field("AWS ID", item.AWSID)
+	field("Message", item.Message)
+	if item.Command != "" {
+		if format == ticketsJira {
+			b.WriteString("\n*Suggested command:*\n{code:bash}\n" + item.Command + "\n{code}\n")
+		} else {
+			b.WriteString("\n**Suggested command:**\n```bash\n" + item.Command + "\n```\n")
+		}
+	}
+	b.WriteString(fmt.Sprintf("\nReport: %s\n", code(path)))
+	if format == ticketsJira {
+		b.WriteString(fmt.Sprintf("Dedup key: %s\n", key))
+	} else {
+		b.WriteString(fmt.Sprintf("<!-- %s:%s -->\n", appName, key))
+	}
+	return b.String()
+}
+
+// writeJiraCSV writes the tickets as a Jira CSV import. Labels repeat their column, which is how
+// the importer takes several values.
+func writeJiraCSV(w io.Writer, tickets []ticket, project string) error {
+	out := csv.NewWriter(w)
+	header := []string{"Summary", "Description", "Issue Type", "Priority", "Labels", "Labels", "Labels"}
+	if project != "" {
+		header = append([]string{"Project Key"}, header...)
+	}
+	if err := out.Write(header); err != nil {
+		return err
+	}
+	for _, t := range tickets {
+		row := append([]string{t.Title, t.Body, "Bug", ticketPriorities[t.Category]}, t.Labels...)
+		if project != "" {
+			row = append([]string{project}, row...)
+		}
+		if err := out.Write(row); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// ticketPayload is what -tickets-push posts for a ticket: a GitHub issue, or a Jira issue in the
+// project.
+func ticketPayload(format, project string, t ticket) interface{} {
+	if format == ticketsGitHub {
+		return map[string]interface{}{"title": t.Title, "body": t.Body, "labels": t.Labels}
+	}
+	return map[string]interface{}{"fields": map[string]interface{}{
+		"project":     map[string]string{"key": project},
+		"summary":     t.Title,
+		"description": t.Body,
+		"issuetype":   map[string]string{"name": "Bug"},
+		"priority":    map[string]string{"name": ticketPriorities[t.Category]},
+		"labels":      t.Labels,
+	}}
+}
+
+// ticketsLedgerPath returns the -tickets-ledger, by default tickets.json in the -save directory.
+func ticketsLedgerPath() string {
+	if path := *figs.String(argTicketsLedger); path != "" {
+		return path
+	}
+	return filepath.Join(*figs.String(argSaveDir), ticketsLedgerFileName)
+}
+
+// loadTicketLedger reads the tickets pushed so far. A missing ledger is empty.
+func loadTicketLedger(path string) (*ticketLedger, error) {
+	l := &ticketLedger{path: path, Endpoints: make(map[string]map[string]ticketLedgerEntry)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, l); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if l.Endpoints == nil {
+		l.Endpoints = make(map[string]map[string]ticketLedgerEntry)
+	}
+	return l, nil
+}
+
+// get returns the ticket pushed to the endpoint for a key, if any.
+func (l *ticketLedger) get(endpoint, key string) (ticketLedgerEntry, bool) {
+	e, ok := l.Endpoints[endpoint][key]
+	return e, ok
+}
+
+// set records the ticket pushed to the endpoint for a key.
+func (l *ticketLedger) set(endpoint, key string, e ticketLedgerEntry) {
+	if l.Endpoints[endpoint] == nil {
+		l.Endpoints[endpoint] = make(map[string]ticketLedgerEntry)
+	}
+	l.Endpoints[endpoint][key] = e
+}
+
+// save writes the ledger through a temporary file.
+func (l *ticketLedger) save() error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return err
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.path)
+}
+
+// pushTicket posts a ticket and returns the link to it the tracker answers with: html_url from
+// GitHub, self from Jira.
+func pushTicket(endpoint, token string, payload interface{}) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	client := &http.Client{Timeout: notifyTimeout}
+	res, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return "", err
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return "", fmt.Errorf("%s answered %s: %s", endpoint, res.Status, strings.TrimSpace(string(body[:min(len(body), 512)])))
+	}
+	var created struct {
+		HTMLURL string `json:"html_url"`
+		Self    string `json:"self"`
+		Key     string `json:"key"`
+	}
+	_ = json.Unmarshal(body, &created)
+	switch {
+	case created.HTMLURL != "":
+		return created.HTMLURL, nil
+	case created.Key != "":
+		return created.Key, nil
+	}
+	return created.Self, nil
+}
+
+// exportTickets prints the tickets of -tickets, or posts the ones not in the ledger of the endpoint
+// yet with -tickets-push. The ledger is saved after every ticket, so a push that fails halfway can
+// be run again. Printing leaves the ledger alone: what is imported from the output is unknown to
+// it, the dedup key in the labels is how the tracker finds those tickets again.
+func exportTickets(format string) error {
+	project := *figs.String(argTicketsProject)
+	tickets, err := collectTickets(*figs.String(argInputFile), format)
+	if err != nil {
+		return err
+	}
+	endpoint := *figs.String(argTicketsPush)
+	if endpoint == "" {
+		if format == ticketsJira {
+			return writeJiraCSV(os.Stdout, tickets, project)
+		}
+		payloads := make([]interface{}, 0, len(tickets))
+		for _, t := range tickets {
+			payloads = append(payloads, ticketPayload(format, project, t))
+		}
+		data, err := json.MarshalIndent(payloads, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if format == ticketsJira && project == "" {
+		return fmt.Errorf("-%s %s needs -%s, the key of the project to create the issues in", argTickets, ticketsJira, argTicketsProject)
+	}
+	ledger, err := loadTicketLedger(ticketsLedgerPath())
+	if err != nil {
+		return err
+	}
+	created := 0
+	for _, t := range tickets {
+		if e, ok := ledger.get(endpoint, t.Key); ok {
+			fmt.Printf("skip   %s %s (pushed as %s)\n", t.Key, t.Title, orDash(e.Ref))
+			continue
+		}
+		ref, err := pushTicket(endpoint, *figs.String(argTicketsToken), ticketPayload(format, project, t))
+		if err != nil {
+			return fmt.Errorf("%s: %w", t.Key, err)
+		}
+		ledger.set(endpoint, t.Key, ticketLedgerEntry{Title: t.Title, Ref: ref, Created: time.Now().UTC()})
+		if err := ledger.save(); err != nil {
+			return err
+		}
+		created++
+		fmt.Printf("create %s %s %s\n", t.Key, t.Title, ref)
+	}
+	fmt.Printf("%d created, %d already pushed, ledger %s\n", created, len(tickets)-created, ledger.path)
+	return nil
+}
+
+// assureTicketFormat is the figtree validator for -tickets.
+func assureTicketFormat(value interface{}) error {
+	var format string
+	switch v := value.(type) {
+	case string:
+		format = v
+	case *string:
+		if v != nil {
+			format = *v
+		}
+	}
+	formats := []string{ticketsGitHub, ticketsJira}
+	if format != "" && !slices.Contains(formats, format) {
+		return fmt.Errorf("-%s: unknown format %q, expected one of %s", argTickets, format, strings.Join(formats, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/csv"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func ticketReports(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeReport(t, dir, "prod", JSONOutput{
+		State: "s3://bucket/prod.tfstate",
+		Results: JSONResults{
+			ErrorResults:     []JSONResultItem{{Resource: "aws_subnet.s1", Kind: "aws_subnet", Message: "lookup failed"}},
+			DangerousResults: []JSONResultItem{{Resource: "aws_s3_bucket.b", Kind: "aws_s3_bucket", Message: "missing in AWS"}},
+			WarningResults:   []JSONResultItem{{Resource: "aws_iam_role.r", Kind: "aws_iam_role", Message: "drift"}},
+		},
+	})
+	return dir
+}
+
+func TestTicketsJiraCSV(t *testing.T) {
+	setFlag(t, argInputFile, ticketReports(t))
+	setFlag(t, argTicketsProject, "OPS")
+	var err error
+	out := captureStdout(t, func() { err = exportTickets(ticketsJira) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || rows[0][0] != "Project Key" || rows[1][0] != "OPS" {
+		t.Fatalf("rows = %q, want a header and the DANGEROUS and ERROR tickets", rows)
+	}
+	if rows[1][1] != "[DANGEROUS] prod: aws_s3_bucket.b" || rows[2][1] != "[ERROR] prod: aws_subnet.s1" {
+		t.Errorf("summaries = %q, %q", rows[1][1], rows[2][1])
+	}
+	key := ticketKey("prod", JSONResultItem{Resource: "aws_s3_bucket.b", Kind: "aws_s3_bucket"})
+	if rows[1][len(rows[1])-1] != key || !strings.Contains(rows[1][2], "Dedup key: "+key) {
+		t.Errorf("ticket does not carry its dedup key %s: %q", key, rows[1])
+	}
+}
+
+func TestTicketsPushDedupsPerEndpoint(t *testing.T) {
+	github := newWebhook(t, http.StatusCreated, `{"html_url": "https://github.example/issues/1"}`)
+	mirror := newWebhook(t, http.StatusCreated, `{"html_url": "https://mirror.example/issues/1"}`)
+	setFlag(t, argInputFile, ticketReports(t))
+	setFlag(t, argTicketsLedger, filepath.Join(t.TempDir(), "tickets.json"))
+
+	push := func(endpoint string) string {
+		t.Helper()
+		setFlag(t, argTicketsPush, endpoint)
+		var err error
+		out := captureStdout(t, func() { err = exportTickets(ticketsGitHub) })
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	if out := push(github.URL); !strings.Contains(out, "2 created, 0 already pushed") {
+		t.Errorf("first push: %s", out)
+	}
+	if out := push(github.URL); !strings.Contains(out, "0 created, 2 already pushed") {
+		t.Errorf("second push: %s", out)
+	}
+	if got := github.posted(); len(got) != 2 {
+		t.Errorf("endpoint got %d tickets, want 2", len(got))
+	}
+	if out := push(mirror.URL); !strings.Contains(out, "2 created, 0 already pushed") {
+		t.Errorf("push to another endpoint: %s", out)
+	}
+
+	ledger, err := loadTicketLedger(ticketsLedgerPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ledger.Endpoints[github.URL]) != 2 || len(ledger.Endpoints[mirror.URL]) != 2 {
+		t.Errorf("ledger = %+v, want 2 tickets per endpoint", ledger.Endpoints)
+	}
+}
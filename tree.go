@@ -0,0 +1,236 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// splitAddress splits a Terraform address on the dots that are not inside an index, so
+// module.a["x.y"].aws_s3_bucket.b[0] becomes module, a["x.y"], aws_s3_bucket, b[0].
+func splitAddress(address string) []string {
+	var parts []string
+	var current strings.Builder
+	depth, quoted := 0, false
+	for _, r := range address {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == '[':
+			depth++
+		case r == ']':
+			depth--
+		case r == '.' && depth == 0:
+			parts = append(parts, current.String())
+			current.Reset()
+			continue
+		}
+		current.WriteRune(r)
+	}
+	return append(parts, current.String())
+}
+
+// parseAddress returns the module path (module.a.module.b) and resource type (aws_s3_bucket, or
+// data.aws_iam_policy_document for data sources) of a Terraform address.
+func parseAddress(address string) (modulePath, resourceType string) {
+	parts := splitAddress(address)
+	var modules []string
+	for i := 0; i < len(parts); i++ {
+		switch {
+		case parts[i] == "module" && i+1 < len(parts):
+			modules = append(modules, "module."+parts[i+1])
+			i++
+		case parts[i] == "data" && i+1 < len(parts):
+			return strings.Join(modules, "."), "data." + parts[i+1]
+		default:
+			return strings.Join(modules, "."), parts[i]
+		}
+	}
+	return strings.Join(modules, "."), ""
+}
+
+// buildLogTree groups execution logs by module path, then resource type, then address.
+func buildLogTree(logs []CommandExecutionLog) *logTree {
+	t := &logTree{nodes: make(map[string]*logTreeNode)}
+	t.add(logs, nil)
+	return t
+}
+
+// add groups logs into the tree. Groups are sorted by name; the logs of an address stay in the
+// order they were added, or are merged by less when it is set. Only the sibling lists that gained a
+// node are sorted again.
+func (t *logTree) add(logs []CommandExecutionLog, less func(a, b CommandExecutionLog) bool) {
+	grown := make(map[*[]*logTreeNode]bool)
+	node := func(nodes *[]*logTreeNode, key, label string, depth int) *logTreeNode {
+		if node, ok := t.nodes[key]; ok {
+			return node
+		}
+		node := &logTreeNode{key: key, label: label, depth: depth}
+		t.nodes[key] = node
+		*nodes = append(*nodes, node)
+		grown[nodes] = true
+		return node
+	}
+
+	resources := make(map[*logTreeNode]bool)
+	for i := range logs {
+		log := &logs[i]
+		address := log.TerraformAddress
+		modulePath, resourceType := parseAddress(address)
+		switch {
+		case address == "":
+			modulePath, resourceType, address = "(no address)", "(no address)", "(no address)"
+		case modulePath == "":
+			modulePath = "(root module)"
+		}
+
+		module := node(&t.roots, modulePath, modulePath, 0)
+		kind := node(&module.children, modulePath+"|"+resourceType, resourceType, 1)
+		resource := node(&kind.children, modulePath+"|"+resourceType+"|"+address, address, 2)
+		leaf := &logTreeNode{key: fmt.Sprintf("%s|%d", resource.key, t.leaves), label: log.Command, depth: 3, log: log}
+		t.leaves++
+		resource.children = append(resource.children, leaf)
+		resources[resource] = true
+		for _, node := range []*logTreeNode{module, kind, resource, leaf} {
+			if log.ExitCode == 0 {
+				node.ok++
+			} else {
+				node.failed++
+			}
+		}
+	}
+
+	for nodes := range grown {
+		sort.Slice(*nodes, func(i, j int) bool { return (*nodes)[i].label < (*nodes)[j].label })
+	}
+	if less != nil {
+		for resource := range resources {
+			leaves := resource.children
+			sort.SliceStable(leaves, func(i, j int) bool { return less(*leaves[i].log, *leaves[j].log) })
+		}
+	}
+}
+
+func (i logTreeItem) Title() string { return i.node.label }
+func (i logTreeItem) Description() string {
+	return fmt.Sprintf("%d ok, %d failed", i.node.ok, i.node.failed)
+}
+func (i logTreeItem) FilterValue() string { return i.node.label }
+
+// render draws the node with its indentation, expander and success/failure counts.
+func (i logTreeItem) render() string {
+	indent := strings.Repeat("  ", i.node.depth)
+	if i.node.log != nil {
+		return fmt.Sprintf("%s  %s exit %d", indent, i.node.label, i.node.log.ExitCode)
+	}
+	expander := "▸"
+	if i.expanded {
+		expander = "▾"
+	}
+	counts := valueStyle.Render(fmt.Sprintf("✓%d", i.node.ok))
+	if i.node.failed > 0 {
+		counts += " " + errorStyle.Render(fmt.Sprintf("✗%d", i.node.failed))
+	}
+	return fmt.Sprintf("%s%s %s  %s", indent, expander, i.node.label, counts)
+}
+
+// loadLogTree rebuilds the tree from the active report and shows the expanded nodes.
+func (m *model) loadLogTree() tea.Cmd {
+	logs, _ := m.visibleLogs()
+	m.logTree = buildLogTree(logs)
+	if m.treeExpanded == nil {
+		m.treeExpanded = make(map[string]bool)
+	}
+	m.refreshLogTree()
+	return nil
+}
+
+// refreshLogTree flattens the visible part of the tree into the list, keeping the selection.
+func (m *model) refreshLogTree() {
+	var items []list.Item
+	var walk func(nodes []*logTreeNode)
+	walk = func(nodes []*logTreeNode) {
+		for _, node := range nodes {
+			expanded := m.treeExpanded[node.key]
+			items = append(items, logTreeItem{node: node, expanded: expanded})
+			if expanded {
+				walk(node.children)
+			}
+		}
+	}
+	walk(m.logTree.roots)
+	index := m.logTreeList.Index()
+	m.logTreeList.Title = fmt.Sprintf("Execution Logs by Module (%d modules)", len(m.logTree.roots))
+	m.logTreeList.SetItems(items)
+	selectClamped(&m.logTreeList, index)
+}
+
+// setTreeExpanded expands or collapses every group node.
+func (m *model) setTreeExpanded(expanded bool) {
+	var walk func(nodes []*logTreeNode)
+	walk = func(nodes []*logTreeNode) {
+		for _, node := range nodes {
+			if node.log == nil {
+				m.treeExpanded[node.key] = expanded
+				walk(node.children)
+			}
+		}
+	}
+	walk(m.logTree.roots)
+	m.refreshLogTree()
+}
+
+func (m model) updateLogTreeView(msg tea.Msg) (model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.logTreeList.FilterState() == list.Filtering {
+			break
+		}
+		item, ok := m.logTreeList.SelectedItem().(logTreeItem)
+		switch {
+		case key.Matches(msg, m.keys.Open, m.keys.Toggle):
+			if !ok {
+				return m, nil
+			}
+			if item.node.log != nil {
+				if key.Matches(msg, m.keys.Open) {
+					m.activeExecutionLog = *item.node.log
+					m.state = m.pushView(viewExecutionLogDetail)
+					m.renderExecutionLogDetail()
+				}
+				return m, nil
+			}
+			m.treeExpanded[item.node.key] = !item.expanded
+			m.refreshLogTree()
+			return m, nil
+		case key.Matches(msg, m.keys.Collapse):
+			if ok && item.expanded {
+				m.treeExpanded[item.node.key] = false
+				m.refreshLogTree()
+			}
+			return m, nil
+		case key.Matches(msg, m.keys.Resource):
+			switch {
+			case ok && item.node.log != nil:
+				return m, m.showResource(item.node.log.TerraformAddress)
+			case ok && item.node.depth == 2: // address nodes always hold at least one log
+				return m, m.showResource(item.node.children[0].log.TerraformAddress)
+			}
+			return m, nil
+		case key.Matches(msg, m.keys.ExpandAll):
+			m.setTreeExpanded(true)
+			return m, nil
+		case key.Matches(msg, m.keys.CollapseAll):
+			m.setTreeExpanded(false)
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.logTreeList, cmd = m.logTreeList.Update(msg)
+	return m, cmd
+}
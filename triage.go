@@ -0,0 +1,457 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// annotationsPath returns the sidecar file that holds the annotations of a report:
+// report.prod.json -> report.prod.annotations.json.
+func annotationsPath(reportPath string) string {
+	return strings.TrimSuffix(reportPath, ".json") + annotationsSuffix
+}
+
+// loadAnnotations reads the sidecar file of a report. A missing file is an empty set, a file that
+// cannot be read or decoded is an error and no set, so it is never saved over.
+func loadAnnotations(reportPath string) (*annotationFile, error) {
+	f := &annotationFile{path: annotationsPath(reportPath), Reports: make(map[string]map[string]annotation)}
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("%s: %w", f.path, err)
+	}
+	if f.Reports == nil {
+		f.Reports = make(map[string]map[string]annotation)
+	}
+	return f, nil
+}
+
+// save writes the sidecar file through a temporary file, so a crash never leaves half of it.
+func (f *annotationFile) save() error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
+
+// annotationReportKey identifies the report run the annotations belong to: the checksum of the
+// JSON report from its backup paths, or the report path when the report has none. The state
+// checksum is not enough, every run against an unchanged state has the same one.
+func annotationReportKey(path string, report *JSONOutput) string {
+	if report.Backup.JsonReportChecksum != "" {
+		return report.Backup.JsonReportChecksum
+	}
+	return path
+}
+
+// get returns the annotation of an item of the report with the given key.
+func (f *annotationFile) get(reportKey, itemKey string) annotation {
+	if f == nil {
+		return annotation{}
+	}
+	return f.Reports[reportKey][itemKey]
+}
+
+// set stores the annotation of an item, removing it when it is empty.
+func (f *annotationFile) set(reportKey, itemKey string, a annotation) {
+	if f.Reports[reportKey] == nil {
+		f.Reports[reportKey] = make(map[string]annotation)
+	}
+	if a.Status == "" && a.Note == "" {
+		delete(f.Reports[reportKey], itemKey)
+		return
+	}
+	a.Updated = time.Now().UTC()
+	f.Reports[reportKey][itemKey] = a
+}
+
+// logKey identifies an execution log by its address and command, which survive a re-run.
+func logKey(log CommandExecutionLog) string {
+	sum := sha256.Sum256([]byte(log.TerraformAddress + "\x00" + log.Command))
+	return "log:" + hex.EncodeToString(sum[:8])
+}
+
+// resultKey identifies a result by its kind and resource; the category may change between runs.
+func resultKey(item JSONResultItem) string {
+	return "result:" + item.Kind + ":" + item.Resource
+}
+
+// badge renders the status and note marker shown in front of an annotated item.
+func (a annotation) badge() string {
+	var badge string
+	switch a.Status {
+	case statusDone:
+		badge = valueStyle.Render("[done]")
+	case statusFollowUp:
+		badge = errorStyle.Render("[follow-up]")
+	case statusIgnore:
+		badge = helpStyle.Render("[ignore]")
+	}
+	if a.Note != "" {
+		badge += keyStyle.Render("✎")
+	}
+	if badge == "" {
+		return ""
+	}
+	return badge + " "
+}
+
+// nextStatus cycles unmarked -> done -> follow-up -> ignore -> unmarked.
+func nextStatus(status string) string {
+	for i, s := range annotationStatuses {
+		if s == status {
+			return annotationStatuses[(i+1)%len(annotationStatuses)]
+		}
+	}
+	return annotationStatuses[0]
+}
+
+// matchesTriage reports whether an annotation passes the triage filter.
+func matchesTriage(filter string, a annotation) bool {
+	switch filter {
+	case "":
+		return true
+	case triageUnmarked:
+		return a.Status == ""
+	default:
+		return a.Status == filter
+	}
+}
+
+// annotations returns the annotations of the active report, reading the sidecar file on first use.
+// It is nil while the sidecar cannot be read, the views then show no annotations.
+func (m *model) annotations() *annotationFile {
+	ws := m.active
+	if ws.annotations == nil && ws.annotationsErr == nil {
+		ws.annotations, ws.annotationsErr = loadAnnotations(ws.path)
+		if ws.annotationsErr != nil {
+			m.setNotification(readOnlyAnnotations(ws.annotationsErr), true)
+		}
+	}
+	return ws.annotations
+}
+
+// writableAnnotations returns the annotations to change or export. A sidecar that could not be
+// read is read again, it may have been fixed or moved since, and stays read-only while it fails.
+func (m *model) writableAnnotations() (*annotationFile, bool) {
+	m.active.annotationsErr = nil
+	f := m.annotations()
+	return f, f != nil
+}
+
+func readOnlyAnnotations(err error) string {
+	return fmt.Sprintf("Annotations are read-only, fix or move the sidecar file: %v", err)
+}
+
+// activeAnnotationKey returns the key of the active report in its annotation file.
+func (m *model) activeAnnotationKey() string {
+	return annotationReportKey(m.active.path, m.report)
+}
+
+// logMark and resultMark look up the annotation of an item of the active report.
+func (m *model) logMark(log CommandExecutionLog) annotation {
+	return m.annotations().get(m.activeAnnotationKey(), logKey(log))
+}
+
+func (m *model) resultMark(item JSONResultItem) annotation {
+	return m.annotations().get(m.activeAnnotationKey(), resultKey(item))
+}
+
+// annotationTarget returns the key and a label of the item the current view annotates.
+func (m model) annotationTarget() (string, string, bool) {
+	switch m.state {
+	case viewMain, viewLinkedLogs:
+		l := m.currentList()
+		if i, ok := l.SelectedItem().(mainItem); ok && !i.placeholder {
+			return logKey(i.log), i.log.Command, true
+		}
+	case viewExecutionLogDetail:
+		return logKey(m.activeExecutionLog), m.activeExecutionLog.Command, true
+	case viewResultsList, viewResultsDetail:
+		if i, ok := m.resultsResourceList.SelectedItem().(resultItem); ok {
+			return resultKey(i.JSONResultItem), i.Resource, true
+		}
+	}
+	return "", "", false
+}
+
+// annotate changes the annotation of the item the current view shows, saves the sidecar file and
+// refreshes the lists and the detail view that show it.
+func (m *model) annotate(change func(*annotation)) tea.Cmd {
+	itemKey, _, ok := m.annotationTarget()
+	if !ok {
+		return nil
+	}
+	f, ok := m.writableAnnotations()
+	if !ok {
+		return m.clearNotificationAfter(5 * time.Second)
+	}
+	a := f.get(m.activeAnnotationKey(), itemKey)
+	change(&a)
+	f.set(m.activeAnnotationKey(), itemKey, a)
+
+	m.refreshLists()
+	if strings.HasPrefix(itemKey, "result:") && !m.selectResult(itemKey) && m.state == viewResultsDetail {
+		// the detail view acts on the selected result, so it must stay in the list
+		m.triage = ""
+		m.refreshLists()
+		m.selectResult(itemKey)
+	}
+	for _, v := range m.viewStack {
+		if v == viewLinkedLogs {
+			index := m.linkedLogList.Index()
+			if i, ok := m.resultsResourceList.SelectedItem().(resultItem); ok {
+				m.loadLinkedLogs(i.JSONResultItem)
+				selectClamped(&m.linkedLogList, index)
+			}
+		}
+	}
+	offset := m.viewPort.YOffset
+	m.redrawView()
+	m.viewPort.SetYOffset(offset)
+
+	if err := f.save(); err != nil {
+		m.setNotification(fmt.Sprintf("Could not save annotations: %v", err), true)
+		return m.clearNotificationAfter(3 * time.Second)
+	}
+	status := a.Status
+	if status == "" {
+		status = triageUnmarked
+	}
+	m.setNotification(fmt.Sprintf("Marked %s, saved to %s", status, f.path), false)
+	return m.clearNotificationAfter(2 * time.Second)
+}
+
+// selectResult selects the result with the given key in the results list. It reports false when
+// the triage filter hides the result.
+func (m *model) selectResult(itemKey string) bool {
+	for i, listItem := range m.resultsResourceList.Items() {
+		if resultKey(listItem.(resultItem).JSONResultItem) == itemKey {
+			m.resultsResourceList.Select(i)
+			return true
+		}
+	}
+	return false
+}
+
+// renderMark renders the triage status and note of an item for the detail views.
+func renderMark(a annotation) string {
+	if a.Status == "" && a.Note == "" {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%s %s\n", titleStyle.Render("Triage:"), a.badge()))
+	if a.Note != "" {
+		b.WriteString(fmt.Sprintf("%s %s\n", titleStyle.Render("Note:"), a.Note))
+	}
+	return b.String()
+}
+
+// updateTriageKeys handles the annotation keys shared by the log and result views.
+func (m model) updateTriageKeys(msg tea.KeyMsg) (model, tea.Cmd, bool) {
+	switch {
+	case key.Matches(msg, m.keys.Mark):
+		return m, m.annotate(func(a *annotation) { a.Status = nextStatus(a.Status) }), true
+	case key.Matches(msg, m.keys.Note):
+		itemKey, label, ok := m.annotationTarget()
+		if !ok {
+			return m, nil, true
+		}
+		f, ok := m.writableAnnotations()
+		if !ok {
+			return m, m.clearNotificationAfter(5 * time.Second), true
+		}
+		m.annotating = label
+		m.textInput.SetValue(f.get(m.activeAnnotationKey(), itemKey).Note)
+		m.textInput.Placeholder = "short note for the next person"
+		m.textInput.Focus()
+		m.state = m.pushView(viewAnnotate)
+		return m, nil, true
+	case key.Matches(msg, m.keys.TriageFilter) && (m.state == viewMain || m.state == viewResultsList):
+		for i, f := range triageFilters {
+			if f == m.triage {
+				m.triage = triageFilters[(i+1)%len(triageFilters)]
+				break
+			}
+		}
+		m.refreshLists()
+		return m, nil, true
+	case key.Matches(msg, m.keys.ExportTriage):
+		f, ok := m.writableAnnotations()
+		if !ok {
+			return m, m.clearNotificationAfter(5 * time.Second), true
+		}
+		path := strings.TrimSuffix(m.active.path, ".json") + ".triage.md"
+		if err := os.WriteFile(path, []byte(triageSummary(m.active.path, m.report, f)), 0644); err != nil {
+			m.setNotification(fmt.Sprintf("Could not write triage summary: %v", err), true)
+			return m, m.clearNotificationAfter(3 * time.Second), true
+		}
+		m.setNotification(fmt.Sprintf("Wrote triage summary to %s", path), false)
+		return m, m.clearNotificationAfter(3 * time.Second), true
+	}
+	return m, nil, false
+}
+
+func (m model) updateAnnotateView(msg tea.Msg) (model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, m.keys.Cancel):
+			m.state = m.popView()
+			return m, nil
+		case key.Matches(msg, m.keys.Submit):
+			note := strings.TrimSpace(m.textInput.Value())
+			m.state = m.popView()
+			return m, m.annotate(func(a *annotation) { a.Note = note })
+		}
+	}
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+// triageSummary renders the annotations of a report as Markdown for the next on-call person:
+// follow-ups first, then what was done and ignored, then what nobody looked at yet.
+func triageSummary(path string, report *JSONOutput, f *annotationFile) string {
+	type entry struct{ label, note string }
+	byStatus := make(map[string][]entry)
+	unmarked := make(map[string]int)
+	var conflicts []entry
+	accepted := 0
+	for _, cat := range resultCategories {
+		results, suppressed := visibleResults(report, cat, false)
+		accepted += suppressed
+		for _, item := range results {
+			label := fmt.Sprintf("%s `%s` (%s)", cat, item.Resource, item.Kind)
+			if action, conflict := plannedResult(cat, item); action != "" {
+				label += fmt.Sprintf(" [plan: %s]", action)
+				if conflict != "" {
+					conflicts = append(conflicts, entry{label, conflict})
+				}
+			}
+			a := f.get(annotationReportKey(path, report), resultKey(item))
+			if a.Status == "" {
+				unmarked[cat]++
+				if a.Note == "" {
+					continue
+				}
+			}
+			byStatus[a.Status] = append(byStatus[a.Status], entry{label, a.Note})
+		}
+	}
+	failed := 0
+	seen := make(map[string]bool)
+	for _, log := range report.ExecutionLogs {
+		k := logKey(log)
+		a := f.get(annotationReportKey(path, report), k)
+		if a.Status == "" && a.Note == "" {
+			if log.ExitCode != 0 {
+				failed++
+			}
+			continue
+		}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		label := fmt.Sprintf("exit %d `%s`", log.ExitCode, log.Command)
+		if log.TerraformAddress != "" {
+			label += fmt.Sprintf(" (%s)", log.TerraformAddress)
+		}
+		byStatus[a.Status] = append(byStatus[a.Status], entry{label, a.Note})
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("# Triage: %s\n\n", path))
+	b.WriteString(fmt.Sprintf("State: %s (checksum %s, version %d)\n", report.State, report.StateChecksum, report.StateVersion))
+	b.WriteString(fmt.Sprintf("Generated: %s\n", time.Now().UTC().Format(time.RFC3339)))
+	if plannedChanges != nil {
+		b.WriteString(fmt.Sprintf("Plan: %s\n", plannedChanges.path))
+		if len(conflicts) > 0 {
+			b.WriteString(fmt.Sprintf("\n## Plan conflicts (%d)\n\n", len(conflicts)))
+			for _, e := range conflicts {
+				b.WriteString(fmt.Sprintf("- %s — %s\n", e.label, e.note))
+			}
+		}
+	}
+	for _, section := range []struct{ status, title string }{
+		{statusFollowUp, "Needs follow-up"},
+		{statusDone, "Done"},
+		{statusIgnore, "Ignored"},
+		{"", "Notes on unmarked items"},
+	} {
+		entries := byStatus[section.status]
+		if len(entries) == 0 {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("\n## %s (%d)\n\n", section.title, len(entries)))
+		for _, e := range entries {
+			b.WriteString("- " + e.label)
+			if e.note != "" {
+				b.WriteString(" — " + e.note)
+			}
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("\n## Not triaged\n\n")
+	for _, cat := range resultCategories {
+		if unmarked[cat] > 0 {
+			b.WriteString(fmt.Sprintf("- %s: %d results\n", cat, unmarked[cat]))
+		}
+	}
+	b.WriteString(fmt.Sprintf("- failed execution logs: %d\n", failed))
+	if accepted > 0 {
+		b.WriteString(fmt.Sprintf("\n%d results are accepted by the baseline and left out.\n", accepted))
+	}
+	return b.String()
+}
+
+// exportTriage prints the triage summary of every -input report, for -triage.
+func exportTriage() error {
+	paths, err := resolveReportInputs(*figs.String(argInputFile))
+	if err != nil {
+		return err
+	}
+	for i, path := range paths {
+		report, err := loadReportData(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		f, err := loadAnnotations(path)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Print(triageSummary(path, report, f))
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAnnotateNeverOverwritesAnUnreadableSidecar(t *testing.T) {
+	m := newNavModel(t, "dev")
+	sidecar := annotationsPath(m.active.path)
+	corrupt := `{"reports": {"abc": {"log:1": {"status": "done"`
+	if err := os.WriteFile(sidecar, []byte(corrupt), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m.active.annotations = nil
+
+	m = press(t, m, "m", "M")
+	assertStack(t, m, "Execution Logs")
+	if !strings.Contains(m.notification, "read-only") {
+		t.Errorf("notification = %q, want the annotations to be read-only", m.notification)
+	}
+	if data, err := os.ReadFile(sidecar); err != nil || string(data) != corrupt {
+		t.Fatalf("sidecar = %q, %v, want it untouched", data, err)
+	}
+
+	// once the sidecar is fixed the next mark saves again
+	if err := os.WriteFile(sidecar, []byte(`{"reports": {}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m = press(t, m, "m")
+	f, err := loadAnnotations(m.active.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := f.get(m.activeAnnotationKey(), logKey(m.report.ExecutionLogs[0])); got.Status != statusDone {
+		t.Errorf("annotation after the fix = %+v, want done", got)
+	}
+}
@@ -1,9 +1,14 @@
 package main
 
 import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"regexp"
 	"sync"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -24,6 +29,23 @@ type (
 
 	viewState int
 
+	// issueSeverity orders schema issues from informational notes to structural errors.
+	issueSeverity int
+
+	// schemaIssue is a single finding from checking a report against the JSONOutput schema.
+	schemaIssue struct {
+		Path     string
+		Message  string
+		Severity issueSeverity
+	}
+
+	// reportMigration upgrades reports written by reconcile-tfstate releases older than before.
+	reportMigration struct {
+		before     string
+		renames    map[string]string // top-level keys, legacy -> current
+		logRenames map[string]string // execution log keys, legacy -> current
+	}
+
 	// CommandExecutionLog stores the result of a single executed command.
 	CommandExecutionLog struct {
 		TerraformAddress string `json:"terraform_address,omitempty"`
@@ -85,9 +107,319 @@ type (
 		ApplicationError string                `json:"application_error,omitempty"`
 	}
 
+	// tfState is the part of a terraform state file needed to look up resource instances.
+	tfState struct {
+		Resources []tfStateResource `json:"resources"`
+	}
+
+	tfStateResource struct {
+		Module    string            `json:"module,omitempty"`
+		Mode      string            `json:"mode"`
+		Type      string            `json:"type"`
+		Name      string            `json:"name"`
+		Instances []tfStateInstance `json:"instances"`
+	}
+
+	tfStateInstance struct {
+		IndexKey   interface{}     `json:"index_key,omitempty"`
+		Attributes json.RawMessage `json:"attributes"`
+	}
+
+	// sessionRun is a command executed from the reader, kept for the resource view.
+	sessionRun struct {
+		at  time.Time
+		log CommandExecutionLog
+	}
+
 	// mainItem represents an item in the primary execution log list.
 	mainItem struct {
-		log CommandExecutionLog
+		log         CommandExecutionLog
+		placeholder bool // "no logs" message rather than a real log
+		mark        annotation
+	}
+
+	// resultItem is an item in the results list, with its triage annotation.
+	resultItem struct {
+		JSONResultItem
+		mark       annotation
+		suppressed bool   // accepted by the -baseline, only listed while suppressed results are shown
+		planned    string // action of the -plan for the resource, "" when it is not in the plan
+		conflict   string // why the planned action is a problem, "" when it is not
+	}
+
+	// annotation is the triage status and note someone left on a log or result.
+	annotation struct {
+		Status  string    `json:"status,omitempty"`
+		Note    string    `json:"note,omitempty"`
+		Updated time.Time `json:"updated"`
+	}
+
+	// baselineEntry accepts a known finding, e.g. a WARNING that is an accepted risk.
+	baselineEntry struct {
+		Resource string `json:"resource"` // terraform address, or a glob such as aws_iam_role.*
+		Kind     string `json:"kind,omitempty"`
+		Category string `json:"category,omitempty"`
+		Reason   string `json:"reason,omitempty"`
+		Expires  string `json:"expires,omitempty"` // YYYY-MM-DD, the last day the entry applies
+	}
+
+	// baseline is the -baseline file. Results it accepts are left out of counts, -fail-on and exports.
+	baseline struct {
+		path    string
+		Entries []baselineEntry `json:"entries"`
+	}
+
+	// historyStore is the -history file: one condensed run per ingested report, oldest first.
+	historyStore struct {
+		path string
+		Runs []historyRun `json:"runs"`
+	}
+
+	// historyRun is what the trends keep of a report. StateChecksum and StateVersion identify it,
+	// or Digest when the report has no state checksum.
+	historyRun struct {
+		Source        string            `json:"source"`
+		State         string            `json:"state"`
+		StateChecksum string            `json:"state_checksum"`
+		StateVersion  uint64            `json:"state_version"`
+		ReportTime    time.Time         `json:"report_time"`
+		Ingested      time.Time         `json:"ingested"`
+		Counts        map[string]int    `json:"counts"`
+		Resources     map[string]string `json:"resources"`        // address -> result category
+		Failed        map[string]int    `json:"failed_commands"`  // command -> failed executions
+		Digest        string            `json:"digest,omitempty"` // SHA-256 of the report, identifies runs without a state checksum
+		Object        string            `json:"object,omitempty"` // s3://bucket/key#etag the report was downloaded from
+	}
+
+	// historyInput is a report to ingest: where it came from, where it is on disk and when it was written.
+	historyInput struct {
+		source, path string
+		object       string // s3://bucket/key#etag of a downloaded report, "" for local files
+		at           time.Time
+	}
+
+	// flappingResource is a resource that changed category more than once across the runs of a state.
+	flappingResource struct {
+		resource   string
+		categories []string // category in every run, "-" where the resource had no result
+		changes    int
+	}
+
+	// failingCommand is a command that failed in more than one run of a state.
+	failingCommand struct {
+		command  string
+		runs     int // runs it failed in
+		failures int // failures across those runs
+	}
+
+	// terraformPlan is the part of terraform show -json <planfile> the reader uses.
+	terraformPlan struct {
+		path            string
+		FormatVersion   string               `json:"format_version"`
+		ResourceChanges []planResourceChange `json:"resource_changes"`
+		actions         map[string]string    // planned action by address
+	}
+
+	planResourceChange struct {
+		Address string `json:"address"`
+		Change  struct {
+			Actions []string `json:"actions"`
+		} `json:"change"`
+	}
+
+	// planConflictRule flags a planned action that goes against what the reconcile found.
+	planConflictRule struct {
+		category, action, message string
+	}
+
+	// stateCommand is a terraform state mv or rm call with its address arguments.
+	stateCommand struct {
+		verb string
+		args []string
+	}
+
+	stateMove struct {
+		from, to string
+	}
+
+	// refactorBlocks are the moved and removed blocks that reproduce a series of state commands.
+	refactorBlocks struct {
+		moves   []stateMove
+		removed []string
+	}
+
+	// replaySource is a report and the execution logs of it to replay.
+	replaySource struct {
+		path   string
+		report *JSONOutput
+		logs   []CommandExecutionLog
+	}
+
+	// server holds the reports of -serve. The mutex guards the execution logs that /api/exec appends to.
+	server struct {
+		mu      sync.RWMutex
+		execMu  sync.Mutex // held while /api/exec runs a command
+		token   string
+		envs    []string
+		reports map[string]*serveReport
+	}
+
+	serveReport struct {
+		env, path string
+		report    *JSONOutput
+	}
+
+	// serveReportInfo lists a report in /api/reports.
+	serveReportInfo struct {
+		Env    string `json:"env"`
+		Path   string `json:"path"`
+		State  string `json:"state"`
+		Region string `json:"region"`
+	}
+
+	// serveMeta is the /api/meta summary of a report.
+	serveMeta struct {
+		Env              string         `json:"env"`
+		Path             string         `json:"path"`
+		State            string         `json:"state"`
+		StateChecksum    string         `json:"state_checksum"`
+		StateVersion     uint64         `json:"state_version"`
+		Region           string         `json:"region"`
+		TFVersion        string         `json:"tf_version"`
+		Version          string         `json:"version"`
+		Concurrency      int            `json:"concurrency"`
+		Arguments        []string       `json:"arguments"`
+		ApplicationError string         `json:"application_error,omitempty"`
+		Logs             int            `json:"logs"`
+		FailedLogs       int            `json:"failed_logs"`
+		Counts           map[string]int `json:"counts"`
+		Suppressed       int            `json:"suppressed"`
+		Exec             bool           `json:"exec"`
+	}
+
+	// serveResult is a result with what the baseline and the -plan say about it.
+	serveResult struct {
+		JSONResultItem
+		Baseline string `json:"baseline,omitempty"`
+		Planned  string `json:"planned,omitempty"`
+		Conflict string `json:"conflict,omitempty"`
+	}
+
+	// serveLog is an execution log with its position in the report.
+	serveLog struct {
+		Index int `json:"index"`
+		CommandExecutionLog
+	}
+
+	// serveLogPage is a page of /api/logs.
+	serveLogPage struct {
+		Total  int        `json:"total"`
+		Offset int        `json:"offset"`
+		Limit  int        `json:"limit"`
+		Logs   []serveLog `json:"logs"`
+	}
+
+	// notifySummary is what -notify templates render: the reports, and Text, the summary as
+	// Markdown.
+	notifySummary struct {
+		App        string         `json:"app"`
+		Generated  time.Time      `json:"generated"`
+		Compared   bool           `json:"compared"` // a -notify-baseline was given
+		Categories []string       `json:"categories"`
+		Reports    []notifyReport `json:"reports"`
+		Text       string         `json:"text"`
+	}
+
+	// notifyReport summarizes a report. Dangerous and Failed hold at most notifyMaxItems entries.
+	notifyReport struct {
+		Env            string           `json:"env"`
+		Path           string           `json:"path"`
+		State          string           `json:"state"`
+		Region         string           `json:"region"`
+		Counts         map[string]int   `json:"counts"`
+		Suppressed     int              `json:"suppressed"`
+		Compared       bool             `json:"compared"`  // a baseline report was found for it
+		Dangerous      []JSONResultItem `json:"dangerous"` // new since the baseline report when compared
+		DangerousTotal int              `json:"dangerous_total"`
+		Logs           int              `json:"logs"`
+		FailedLogs     int              `json:"failed_logs"`
+		Failed         []notifyFailure  `json:"failed"`
+		FailedTotal    int              `json:"failed_total"`
+	}
+
+	// notifyFailure is a command that failed, with how many times it did.
+	notifyFailure struct {
+		Command  string `json:"command"`
+		Address  string `json:"address,omitempty"`
+		ExitCode int    `json:"exit_code"`
+		Count    int    `json:"count"`
+	}
+
+	// ticket is an issue filed for a result. Key stays the same across runs and dedups it.
+	ticket struct {
+		Key      string
+		Env      string
+		Category string
+		Title    string
+		Body     string
+		Labels   []string
+	}
+
+	// ticketLedger is the -tickets-ledger file: the tickets -tickets-push created, by endpoint and
+	// then by key, so pushing to another tracker files them again.
+	ticketLedger struct {
+		path      string
+		Endpoints map[string]map[string]ticketLedgerEntry `json:"endpoints"`
+	}
+
+	ticketLedgerEntry struct {
+		Title   string    `json:"title"`
+		Ref     string    `json:"ref"` // link or key the tracker answered with
+		Created time.Time `json:"created"`
+	}
+
+	// metricWriter collects gauges in the order they are first added.
+	metricWriter struct {
+		families []*metricFamily
+		byName   map[string]*metricFamily
+	}
+
+	metricFamily struct {
+		name, help string
+		samples    []metricSample
+	}
+
+	metricSample struct {
+		labels []string // name, value pairs
+		value  float64
+	}
+
+	// redactRule is a secret detector. A named group "keep" is left in place, e.g. the key of a key = value pair.
+	redactRule struct {
+		name string
+		re   *regexp.Regexp
+	}
+
+	// redactor masks secrets in everything the reader shows, copies and exports.
+	redactor struct {
+		rules []redactRule
+	}
+
+	// annotationFile is the sidecar file next to a report that holds its annotations, by report
+	// checksum and then by item key, so another run never inherits them.
+	annotationFile struct {
+		path    string
+		Reports map[string]map[string]annotation `json:"reports"`
+	}
+
+	// logSortKey is the order of the execution log list.
+	logSortKey int
+
+	// logFacets are the toggles that narrow down the execution log list.
+	logFacets struct {
+		failedOnly bool
+		githubOnly bool
+		hasStderr  bool
 	}
 
 	// backupItem represents a key-value pair from the backup configuration.
@@ -97,8 +429,99 @@ type (
 
 	// resultCategoryItem represents a category in the results view.
 	resultCategoryItem struct {
-		name  string
-		count int
+		name       string
+		count      int
+		suppressed int // accepted by the -baseline and not in count
+		conflicts  int // results whose -plan action conflicts with them
+	}
+
+	// logTreeNode is a module, resource type, address or single execution log in the log tree.
+	logTreeNode struct {
+		key, label string
+		depth      int
+		ok, failed int
+		children   []*logTreeNode
+		log        *CommandExecutionLog // set on leaves only
+	}
+
+	// logTree groups execution logs by module path, then resource type, then address. Its nodes are
+	// indexed by key, so logs are added to the tree as they are streamed instead of rebuilding it.
+	logTree struct {
+		roots  []*logTreeNode
+		nodes  map[string]*logTreeNode // group nodes by key
+		leaves int                     // numbers the leaves, so every log has a key of its own
+	}
+
+	// logTreeItem represents a visible node of the log tree.
+	logTreeItem struct {
+		node     *logTreeNode
+		expanded bool
+	}
+
+	// environmentItem represents one report of a multi-report workspace.
+	environmentItem struct {
+		ws *workspaceReport
+	}
+
+	// workspaceReport is one report loaded from -input, named after its environment.
+	workspaceReport struct {
+		env, path string
+		report    *JSONOutput
+		issues    []schemaIssue
+
+		// background loading of execution logs, started when the report is first opened
+		stream      <-chan logBatchMsg
+		loading     bool
+		read, total int64
+
+		// displayedLog of the first execution logs, filled as searches need them
+		displayed []CommandExecutionLog
+
+		// local state file entries by address, read when the resource view first needs them
+		state    map[string]json.RawMessage
+		stateErr error
+
+		// triage annotations from the sidecar file, read when first needed
+		annotations    *annotationFile
+		annotationsErr error
+
+		// file state of the last load, compared by -watch
+		modTime   time.Time
+		size      int64
+		reloading bool
+	}
+
+	// searchState is the full-text search applied to the execution logs.
+	searchState struct {
+		query   string
+		re      *regexp.Regexp
+		matches int   // matches in the execution logs the list shows
+		hits    []int // lines of the rendered detail view that contain a match
+		current int   // index into hits of the match shown last, -1 before the first jump
+	}
+
+	// resourceCategories maps each environment to the result category of one resource.
+	resourceCategories struct {
+		name       string
+		categories map[string]string
+	}
+
+	// profile is a named execution context from the -profiles file. Commands
+	// run in its terraform directory against its state, with its AWS profile and region.
+	profile struct {
+		Name         string            `yaml:"-"`
+		TfDir        string            `yaml:"tf_dir"`
+		TfState      string            `yaml:"tf_state"`
+		AWSProfile   string            `yaml:"aws_profile"`
+		AWSRegion    string            `yaml:"aws_region"`
+		ReportRegion string            `yaml:"report_region"` // region the reports must be for, defaults to aws_region
+		Env          map[string]string `yaml:"env"`
+	}
+
+	// profileItem is a profile in the switcher, the zero profile stands for the environment.
+	profileItem struct {
+		profile
+		active bool
 	}
 
 	// configItem represents an environment variable for configuration.
@@ -106,6 +529,79 @@ type (
 		key, val string
 	}
 
+	// keyMap holds every key binding of the TUI. The help footer and the ? overlay are generated
+	// from it, and each binding can be overridden by name with -keys.
+	keyMap struct {
+		// global
+		ForceQuit key.Binding
+		Quit      key.Binding
+		Back      key.Binding
+		Help      key.Binding
+		Root      key.Binding
+		Up        key.Binding
+		Down      key.Binding
+
+		// lists
+		Open   key.Binding
+		Select key.Binding
+
+		// execution log list
+		Search     key.Binding
+		Group      key.Binding
+		Sort       key.Binding
+		FailedOnly key.Binding
+		GitHubOnly key.Binding
+		HasStderr  key.Binding
+		Backups    key.Binding
+		Results    key.Binding
+		Configs    key.Binding
+		Trend      key.Binding
+		Profiles   key.Binding
+
+		// detail views
+		Top       key.Binding
+		Bottom    key.Binding
+		NextMatch key.Binding
+		PrevMatch key.Binding
+		Copy      key.Binding
+		Edit      key.Binding
+		Result    key.Binding
+		Resource  key.Binding
+		Execute   key.Binding
+		Preview   key.Binding
+		Logs      key.Binding
+
+		// tree and environments
+		Toggle      key.Binding
+		Collapse    key.Binding
+		ExpandAll   key.Binding
+		CollapseAll key.Binding
+		Summary     key.Binding
+
+		// results
+		ShowSuppressed key.Binding
+
+		// triage
+		Mark         key.Binding
+		Note         key.Binding
+		TriageFilter key.Binding
+		ExportTriage key.Binding
+
+		// refactor
+		ExportRefactor key.Binding
+		ExportReplay   key.Binding
+
+		// text inputs
+		Submit key.Binding
+		Cancel key.Binding
+	}
+
+	// keyGroup is a titled section of the help overlay.
+	keyGroup struct {
+		title    string
+		bindings []key.Binding
+	}
+
 	// --- TUI list delegate ---
 
 	itemDelegate struct{}
@@ -122,6 +618,50 @@ type (
 		err            error
 	}
 
+	watchTickMsg struct{}
+
+	// planPreviewMsg carries the outcome of an import plan in a scratch directory.
+	planPreviewMsg struct {
+		address, command string
+		stdout, stderr   string
+		generated        string // HCL written by -generate-config-out
+		err              error
+	}
+
+	// reportReloadedMsg carries a report that was decoded again after its file changed.
+	reportReloadedMsg struct {
+		ws     *workspaceReport
+		report *JSONOutput
+		issues []schemaIssue
+		info   os.FileInfo
+		err    error
+	}
+
+	// reportFile is an open report whose top-level fields were read. logsAt holds the offset of
+	// every key that can hold the execution logs, which were skipped, so they can be decoded from the
+	// open file afterwards.
+	reportFile struct {
+		*os.File
+		fields map[string]json.RawMessage
+		logsAt map[string]int64
+	}
+
+	// reportScanner frames the top-level JSON values of a report and counts the bytes it consumed.
+	reportScanner struct {
+		r      *bufio.Reader
+		offset int64
+	}
+
+	// logBatchMsg carries execution logs decoded in the background by streamExecutionLogs.
+	logBatchMsg struct {
+		report      *JSONOutput
+		stream      <-chan logBatchMsg // the stream the batch came from, to wait for the next one
+		logs        []CommandExecutionLog
+		read, total int64 // bytes of the input file consumed so far / in total
+		done        bool
+		err         error
+	}
+
 	versions struct {
 		tf, bfsm, bsmr string
 	}
@@ -129,6 +669,8 @@ type (
 	// --- MODEL ---
 	model struct {
 		// common
+		workspace   []*workspaceReport
+		active      *workspaceReport
 		report      *JSONOutput
 		errorCounts *sync.Map
 		versions    versions
@@ -139,29 +681,47 @@ type (
 		err         error
 
 		// components
+		environmentList     list.Model
 		mainList            list.Model
+		logTreeList         list.Model
+		linkedLogList       list.Model
 		backupList          list.Model
 		resultsCategoryList list.Model
 		resultsResourceList list.Model
 		configList          list.Model
+		profileList         list.Model
 		viewPort            viewport.Model
 		spinNer             spinner.Model
 		textInput           textinput.Model
 
 		// ui state
 		notification string
-		quitMessage  string // for final message on exit
 		viewStack    []viewState
+		keys         keyMap
 
 		// view-specific data
 		activeResultCategory string
 		activeBackupItem     backupItem
 		activeExecutionLog   CommandExecutionLog
 		activeConfigItem     configItem
+		activeResource       string
+		sessionRuns          []sessionRun
+		search               searchState
+		logSort              logSortKey
+		facets               logFacets
+		triage               string // triage filter, "" for every item
+		showSuppressed       bool   // list the results the baseline accepts
+		annotating           string // label of the item whose note is being edited
+		profile              string // name of the profile commands run with, "" for the environment
+		planPreview          planPreviewMsg
+		planRunning          bool
+		logTree              *logTree
+		treeExpanded         map[string]bool
 		deleteConfirmPath    string
 		deleteTimer          *time.Timer
 		commandRunner        struct {
 			cmd, stdout, stderr string
+			address             string // terraform address of the result the command was run for
 			exitError           error
 		}
 	}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -15,23 +14,34 @@ import (
 	"github.com/charmbracelet/bubbles/list"
 )
 
-// loadReportData reads the specified JSON file and unmarshals it into our JSONOutput struct.
+// loadReportData reads the specified JSON file and unmarshals it into our JSONOutput struct,
+// migrating older report formats and warning on stderr about fields that do not match the schema.
 func loadReportData(filePath string) (*JSONOutput, error) {
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("input file not found: %s", filePath)
+	rf, err := openReport(filePath)
+	if err != nil {
+		return nil, err
 	}
-
-	data, err := os.ReadFile(filePath)
+	defer rf.Close()
+	report, issues, err := rf.meta()
 	if err != nil {
-		return nil, fmt.Errorf("could not read input file: %w", err)
+		return nil, err
+	}
+	if n := countIssues(issues, issueWarning); n > 0 {
+		for _, issue := range issues {
+			if issue.Severity >= issueWarning {
+				_, _ = fmt.Fprintf(os.Stderr, "%s: %s\n", issue.Severity, issue)
+			}
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "%s: %d schema warning(s), run with -%s for details\n", filePath, n, argValidate)
 	}
 
-	var report JSONOutput
-	if err := json.Unmarshal(data, &report); err != nil {
+	_, err = rf.decodeExecutionLogs(report.Version, func(log CommandExecutionLog, _ int64) {
+		report.ExecutionLogs = append(report.ExecutionLogs, log)
+	})
+	if err != nil {
 		return nil, fmt.Errorf("could not parse JSON from input file: %w", err)
 	}
-
-	return &report, nil
+	return report, nil
 }
 
 func isValidPath(val string) bool {
@@ -57,32 +67,20 @@ var check = func(what interface{}) {
 	}
 }
 
-// aggregateErrors scans execution logs for common error patterns and counts them.
+// aggregateErrors scans execution logs for common error patterns and counts them. The logs are
+// scanned in one goroutine, the counts are plain ints that concurrent scans would race on.
 func aggregateErrors(report *JSONOutput) *sync.Map {
 	errorCounts := &sync.Map{}
-	if report.ExecutionLogs == nil {
-		return errorCounts
-	}
-
-	var wg sync.WaitGroup
 	for _, log := range report.ExecutionLogs {
-		wg.Add(1)
-		go func(log CommandExecutionLog) {
-			defer wg.Done()
-			// Combine stdout and stderr for searching
-			output := log.Stdout + "\n" + log.Stderr
-			for _, errStr := range commonTerraformErrors {
-				if strings.Contains(output, errStr) {
-					// We found an error pattern.
-					// Load the existing count, increment it, and store it back.
-					val, _ := errorCounts.LoadOrStore(errStr, &ErrorCount{Error: errStr, Count: 0})
-					count := val.(*ErrorCount)
-					count.Count++
-				}
+		// Combine stdout and stderr for searching
+		output := log.Stdout + "\n" + log.Stderr
+		for _, errStr := range commonTerraformErrors {
+			if strings.Contains(output, errStr) {
+				val, _ := errorCounts.LoadOrStore(errStr, &ErrorCount{Error: errStr, Count: 0})
+				val.(*ErrorCount).Count++
 			}
-		}(log)
+		}
 	}
-	wg.Wait()
 	return errorCounts
 }
 
@@ -1,6 +1,10 @@
 package main
 
 import (
+	"reflect"
+	"regexp"
+	"time"
+
 	"github.com/andreimerlescu/figtree/v2"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -8,13 +12,70 @@ import (
 var (
 	figs figtree.Plant
 
-	reportData *JSONOutput
+	// knownFindings is the -baseline file, nil without one.
+	knownFindings *baseline
+
+	// secrets redacts logs and results as they load, nil with -no-redact.
+	secrets *redactor
+
+	// profiles are the execution contexts of the -profiles file, by name.
+	profiles map[string]profile
+
+	// plannedChanges is the -plan file, nil without one.
+	plannedChanges *terraformPlan
+
+	// planConflictRules are the planned actions that contradict a result.
+	planConflictRules = []planConflictRule{
+		{"POTENTIAL_IMPORT", planCreate, "the plan creates a resource that already exists in AWS, import it first or the apply makes a duplicate"},
+		{"POTENTIAL_IMPORT", planReplace, "the plan replaces a resource that already exists in AWS, import it first or the apply makes a duplicate"},
+		{"OK", planDelete, "the plan destroys a resource that is in sync"},
+		{"OK", planReplace, "the plan replaces a resource that is in sync"},
+		{"DANGEROUS", planNoOp, "the plan expects a resource that is missing in AWS to be unchanged"},
+		{"DANGEROUS", planUpdate, "the plan updates a resource that is missing in AWS"},
+	}
+
+	// secretDetectors are the built-in redactions, applied before the -redact patterns.
+	secretDetectors = []redactRule{
+		{name: "private-key", re: regexp.MustCompile(`(?s)-----BEGIN [A-Z0-9 ]*PRIVATE KEY-----.*?-----END [A-Z0-9 ]*PRIVATE KEY-----`)},
+		{name: "aws-access-key-id", re: regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA|AGPA|AIDA|AIPA|ANPA|ANVA|AROA|APKA)[A-Z0-9]{16}\b`)},
+		{name: "aws-secret-access-key", re: regexp.MustCompile(`(?i)(?P<keep>(?:aws_?)?secret_?access_?key["']?\s*[:=]\s*["']?)[A-Za-z0-9/+=]{40,}`)},
+		{name: "aws-session-token", re: regexp.MustCompile(`(?i)(?P<keep>(?:aws_?)?session_?token["']?\s*[:=]\s*["']?)[A-Za-z0-9/+=]{16,}`)},
+		{name: "bearer-token", re: regexp.MustCompile(`(?i)(?P<keep>\bbearer\s+)[A-Za-z0-9\-._~+/]{8,}=*`)},
+		{name: "github-token", re: regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+		{name: "sensitive-value", re: regexp.MustCompile(`(?i)(?P<keep>"?\b(?:password|passwd|secret|token|api_?key|private_?key)"?\s*[:=]\s*)"[^"\n]+"`)},
+	}
+
+	// notifyTemplates are the built-in -notify payloads. Slack reads mrkdwn from text, Teams
+	// workflows take an Adaptive Card, and json sends the whole summary.
+	notifyTemplates = map[string]string{
+		"slack": `{"text": {{json (printf "*%s*\n%s" .App .Text)}}}`,
+		"teams": `{"type": "message", "attachments": [{"contentType": "application/vnd.microsoft.card.adaptive", "content": {` +
+			`"$schema": "http://adaptivecards.io/schemas/adaptive-card.json", "type": "AdaptiveCard", "version": "1.4", "body": [` +
+			`{"type": "TextBlock", "size": "Medium", "weight": "Bolder", "text": {{json .App}}},` +
+			`{"type": "TextBlock", "wrap": true, "text": {{json .Text}}}]}}]}`,
+		"json": `{{json .}}`,
+	}
+
+	// ticketCategories are the result categories -tickets files tickets for.
+	ticketCategories = []string{"DANGEROUS", "ERROR"}
+
+	// ticketPriorities maps the category of a ticket to its Jira priority.
+	ticketPriorities = map[string]string{"DANGEROUS": "High", "ERROR": "Medium"}
+
+	// notifyTimeout bounds the webhook request of -notify and each request of -tickets-push.
+	notifyTimeout = 15 * time.Second
 
 	// resultCategories provides a consistent order for iterating through result types.
 	resultCategories = []string{
 		"INFO", "OK", "POTENTIAL_IMPORT", "REGION_MISMATCH", "WARNING", "ERROR", "DANGEROUS",
 	}
 
+	// annotationStatuses is the order m cycles through, starting from unmarked.
+	annotationStatuses = []string{"", statusDone, statusFollowUp, statusIgnore}
+
+	// triageFilters is the order T cycles through, starting from every item.
+	triageFilters = []string{"", triageUnmarked, statusDone, statusFollowUp, statusIgnore}
+
 	titleStyle         = lipgloss.NewStyle().MarginLeft(2).Bold(true).Foreground(lipgloss.Color("205"))
 	itemStyle          = lipgloss.NewStyle().PaddingLeft(2)
 	selectedItemStyle  = lipgloss.NewStyle().PaddingLeft(0).Foreground(lipgloss.Color("170"))
@@ -26,6 +87,19 @@ var (
 	confirmPromptStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("220"))
 	codeStyle          = lipgloss.NewStyle().Foreground(lipgloss.Color("202")).Background(lipgloss.Color("236")).Padding(0, 1)
 	boldStyle          = lipgloss.NewStyle().Bold(true)
+	matchStyle         = lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("220"))
+
+	// reportMigrations rename the fields of older reconcile-tfstate reports to the current format,
+	// oldest first. No release has renamed a field of the report yet.
+	reportMigrations []reportMigration
+
+	// requiredFields are the fields a report is of no use without, by the type they belong to. A
+	// missing required field is structural, any other missing field only a warning.
+	requiredFields = map[reflect.Type][]string{
+		reflect.TypeOf(JSONOutput{}):          {"state", "results"},
+		reflect.TypeOf(CommandExecutionLog{}): {"command"},
+		reflect.TypeOf(JSONResultItem{}):      {"resource", "kind"},
+	}
 
 	// Common terraform error strings to look for.
 	commonTerraformErrors = []string{
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// watchReports ticks every interval so the model can check the report files for changes.
+func watchReports(interval time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return watchTickMsg{}
+	})
+}
+
+// reportChanged stats the report file and says whether it differs from what was last loaded.
+func (ws *workspaceReport) reportChanged() (os.FileInfo, bool) {
+	info, err := os.Stat(ws.path)
+	if err != nil {
+		return nil, false
+	}
+	return info, !info.ModTime().Equal(ws.modTime) || info.Size() != ws.size
+}
+
+// reloadReport decodes the report file again in the background. Reports whose logs were never
+// opened only have their metadata reloaded; opening them streams the logs from the new file.
+func reloadReport(ws *workspaceReport, info os.FileInfo) tea.Cmd {
+	withLogs := ws.stream != nil
+	return func() tea.Msg {
+		rf, err := openReport(ws.path)
+		if err != nil {
+			return reportReloadedMsg{ws: ws, info: info, err: err}
+		}
+		defer rf.Close()
+		report, issues, err := rf.meta()
+		if err != nil {
+			return reportReloadedMsg{ws: ws, info: info, err: err}
+		}
+		if withLogs {
+			_, err := rf.decodeExecutionLogs(report.Version, func(log CommandExecutionLog, _ int64) {
+				report.ExecutionLogs = append(report.ExecutionLogs, log)
+			})
+			if err != nil {
+				return reportReloadedMsg{ws: ws, info: info, err: err}
+			}
+		}
+		return reportReloadedMsg{ws: ws, report: report, issues: issues, info: info}
+	}
+}
+
+// checkReports starts a reload of every report whose file changed since it was loaded.
+func (m *model) checkReports() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, ws := range m.workspace {
+		if ws.loading || ws.reloading {
+			continue
+		}
+		if info, changed := ws.reportChanged(); changed {
+			ws.reloading = true
+			cmds = append(cmds, reloadReport(ws, info))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// applyReload swaps the reloaded report in place, so every view that holds the pointer sees the
+// new data, and keeps list selections and the scroll position where they were.
+func (m *model) applyReload(msg reportReloadedMsg) tea.Cmd {
+	ws := msg.ws
+	ws.reloading = false
+	ws.modTime, ws.size = msg.info.ModTime(), msg.info.Size()
+	if msg.err != nil {
+		// reconcile-tfstate may still be writing the file, the next change triggers another attempt.
+		m.setNotification(fmt.Sprintf("Could not reload %s: %v", ws.path, msg.err), true)
+		return m.clearNotificationAfter(3 * time.Second)
+	}
+
+	addedLogs := len(msg.report.ExecutionLogs) - len(ws.report.ExecutionLogs)
+	addedResults := countResults(msg.report) - countResults(ws.report)
+	if ws.stream == nil {
+		addedLogs = 0
+	}
+	*ws.report = *msg.report
+	ws.issues = msg.issues
+	ws.state, ws.stateErr = nil, nil
+	ws.displayed = nil
+
+	if m.ready {
+		m.loadEnvironmentList()
+		if ws == m.active {
+			m.errorCounts = aggregateErrors(ws.report)
+			m.versions.tf, m.versions.bfsm = ws.report.TFVersion, ws.report.Version
+			m.refreshLists()
+		}
+		if m.state == viewEnvironmentSummary {
+			offset := m.viewPort.YOffset
+			m.renderEnvironmentSummary()
+			m.viewPort.SetYOffset(offset)
+		}
+		if m.state == viewResource && ws == m.active {
+			offset := m.viewPort.YOffset
+			m.renderResource()
+			m.viewPort.SetYOffset(offset)
+		}
+	}
+	m.setNotification(fmt.Sprintf("Reloaded %s: %+d execution logs, %+d results", ws.path, addedLogs, addedResults), false)
+	return m.clearNotificationAfter(3 * time.Second)
+}
+
+// refreshLists reloads the lists of the active report while keeping their selections on the same
+// items, wherever the reload moved them.
+func (m *model) refreshLists() {
+	mainIndex, mainKey := m.mainList.Index(), listItemKey(m.mainList.SelectedItem())
+	m.loadMainList()
+	selectByKey(&m.mainList, mainKey, mainIndex)
+
+	backupIndex, backupKey := m.backupList.Index(), listItemKey(m.backupList.SelectedItem())
+	m.loadBackupList()
+	selectByKey(&m.backupList, backupKey, backupIndex)
+
+	categoryIndex, categoryKey := m.resultsCategoryList.Index(), listItemKey(m.resultsCategoryList.SelectedItem())
+	m.loadResultsCategories()
+	selectByKey(&m.resultsCategoryList, categoryKey, categoryIndex)
+
+	if m.logTree != nil {
+		m.loadLogTree()
+	}
+
+	if m.activeResultCategory != "" {
+		resultIndex, selectedResult := m.resultsResourceList.Index(), listItemKey(m.resultsResourceList.SelectedItem())
+		m.loadResultsList()
+		selectByKey(&m.resultsResourceList, selectedResult, resultIndex)
+	}
+}
+
+// listItemKey identifies a list item across reloads, "" for items without an identity.
+func listItemKey(item list.Item) string {
+	switch i := item.(type) {
+	case mainItem:
+		if i.placeholder {
+			return ""
+		}
+		return logKey(i.log)
+	case resultItem:
+		return resultKey(i.JSONResultItem)
+	case backupItem:
+		return i.key
+	case resultCategoryItem:
+		return i.name
+	}
+	return ""
+}
+
+// selectByKey selects the item with the key, the one closest to index when the same command was
+// logged more than once. When the item is gone, it falls back to the clamped index.
+func selectByKey(l *list.Model, key string, index int) {
+	if key != "" {
+		found := -1
+		for i, item := range l.Items() {
+			if listItemKey(item) != key {
+				continue
+			}
+			if found < 0 || abs(i-index) < abs(found-index) {
+				found = i
+			}
+		}
+		if found >= 0 {
+			l.Select(found)
+			return
+		}
+	}
+	selectClamped(l, index)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// selectClamped selects index, or the last item when the list got shorter.
+func selectClamped(l *list.Model, index int) {
+	if n := len(l.Items()); index >= n {
+		index = n - 1
+	}
+	if index < 0 {
+		index = 0
+	}
+	l.Select(index)
+}
+
+// countResults returns the number of results across every category.
+func countResults(report *JSONOutput) int {
+	total := 0
+	for _, cat := range resultCategories {
+		total += len(report.Results.GetCategory(cat))
+	}
+	return total
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// resolveReportInputs expands -input into report files. It accepts a single file, a directory
+// (every *.json in it) or a glob pattern.
+func resolveReportInputs(input string) ([]string, error) {
+	var paths []string
+	switch info, err := os.Stat(input); {
+	case err == nil && info.IsDir():
+		matches, err := filepath.Glob(filepath.Join(input, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		paths = matches
+	case err == nil:
+		return []string{input}, nil
+	case strings.ContainsAny(input, "*?["):
+		matches, err := filepath.Glob(input)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -%s pattern %q: %w", argInputFile, input, err)
+		}
+		paths = matches
+	default:
+		return nil, fmt.Errorf("input file not found: %s", input)
+	}
+	// the annotation sidecar files sit next to the reports and match *.json too, and so do the
+	// history store and the ticket ledger in the -save directory
+	reports := paths[:0]
+	for _, path := range paths {
+		if isReportFile(path) {
+			reports = append(reports, path)
+		}
+	}
+	paths = reports
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no reports match %s", input)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// isReportFile reports whether a JSON file matched by a directory or glob input may be a report,
+// rather than a file the reader writes itself.
+func isReportFile(path string) bool {
+	switch filepath.Base(path) {
+	case historyFileName, ticketsLedgerFileName:
+		return false
+	}
+	return !strings.HasSuffix(path, annotationsSuffix)
+}
+
+// assureReportInput is the figtree validator for -input.
+func assureReportInput(value interface{}) error {
+	input, ok := value.(string)
+	if !ok {
+		if p, isPtr := value.(*string); isPtr && p != nil {
+			input = *p
+		}
+	}
+	if input == "" {
+		return fmt.Errorf("-%s must not be empty", argInputFile)
+	}
+	if info, err := os.Stat(input); err == nil && info.IsDir() {
+		return nil
+	}
+	if strings.ContainsAny(input, "*?[") || strings.HasSuffix(input, ".json") {
+		return nil
+	}
+	return fmt.Errorf("-%s must be a .json report, a directory of reports or a glob", argInputFile)
+}
+
+// reportEnvironment derives the environment name from a report path: report.<env>.json -> <env>.
+func reportEnvironment(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), ".json")
+	if env, ok := strings.CutPrefix(name, "report."); ok && env != "" {
+		return env
+	}
+	return name
+}
+
+// resolveEnvironmentReports expands input like resolveReportInputs for the modes that name every
+// report after its environment. Two reports of one environment, e.g. report.prod.json in two
+// directories, would be mixed up in those, so they are an error.
+func resolveEnvironmentReports(input string) ([]string, error) {
+	paths, err := resolveReportInputs(input)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]string, len(paths))
+	for _, path := range paths {
+		env := reportEnvironment(path)
+		if other, ok := seen[env]; ok {
+			return nil, fmt.Errorf("%s and %s are both the %s environment, pass them separately", other, path, env)
+		}
+		seen[env] = path
+	}
+	return paths, nil
+}
+
+// loadWorkspace decodes the metadata of every report matched by input. Execution logs are only
+// streamed once a report is opened.
+func loadWorkspace(input string) ([]*workspaceReport, error) {
+	paths, err := resolveEnvironmentReports(input)
+	if err != nil {
+		return nil, err
+	}
+	workspace := make([]*workspaceReport, 0, len(paths))
+	for _, path := range paths {
+		report, issues, err := loadReportMeta(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		ws := &workspaceReport{
+			env:    reportEnvironment(path),
+			path:   path,
+			report: report,
+			issues: issues,
+		}
+		if info, err := os.Stat(path); err == nil {
+			ws.modTime, ws.size = info.ModTime(), info.Size()
+		}
+		workspace = append(workspace, ws)
+	}
+	return workspace, nil
+}
+
+func (i environmentItem) Title() string { return i.ws.env }
+func (i environmentItem) Description() string {
+	var counts []string
+	for _, cat := range []string{"DANGEROUS", "ERROR", "WARNING"} {
+		results, _ := visibleResults(i.ws.report, cat, false)
+		counts = append(counts, fmt.Sprintf("%s %d", cat, len(results)))
+	}
+	return fmt.Sprintf("%s | %s", i.ws.path, strings.Join(counts, " | "))
+}
+func (i environmentItem) FilterValue() string { return i.ws.env }
+
+// workspaceFor returns the workspace entry that holds report.
+func (m *model) workspaceFor(report *JSONOutput) *workspaceReport {
+	for _, ws := range m.workspace {
+		if ws.report == report {
+			return ws
+		}
+	}
+	return nil
+}
+
+// openReport makes ws the report every view works on and starts streaming its execution logs
+// the first time it is opened.
+func (m *model) openReport(ws *workspaceReport) tea.Cmd {
+	m.active = ws
+	m.report = ws.report
+	m.versions.tf = ws.report.TFVersion
+	m.versions.bfsm = ws.report.Version
+	m.errorCounts = aggregateErrors(ws.report)
+	if m.ready {
+		m.loadMainList()
+		m.mainList.Select(0)
+	}
+	if ws.stream != nil {
+		return nil
+	}
+	ws.stream = streamExecutionLogs(ws.report, ws.path)
+	ws.loading = true
+	return waitForLogBatch(ws.stream)
+}
+
+func (m *model) loadEnvironmentList() tea.Cmd {
+	items := make([]list.Item, len(m.workspace))
+	for i, ws := range m.workspace {
+		items[i] = environmentItem{ws: ws}
+	}
+	m.environmentList.Title = fmt.Sprintf("Environments (%d)", len(m.workspace))
+	m.environmentList.SetItems(items)
+	return nil
+}
+
+func (m model) updateEnvironmentsView(msg tea.Msg) (model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.environmentList.FilterState() == list.Filtering {
+			break
+		}
+		switch {
+		case key.Matches(msg, m.keys.Open):
+			if i, ok := m.environmentList.SelectedItem().(environmentItem); ok {
+				cmd := m.openReport(i.ws)
+				m.state = m.pushView(viewMain)
+				return m, cmd
+			}
+			return m, nil
+		case key.Matches(msg, m.keys.Summary):
+			m.state = m.pushView(viewEnvironmentSummary)
+			m.renderEnvironmentSummary()
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.environmentList, cmd = m.environmentList.Update(msg)
+	return m, cmd
+}
+
+func (m model) updateEnvironmentSummaryView(msg tea.Msg) (model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.viewPort, cmd = m.viewPort.Update(msg)
+	return m, cmd
+}
+
+// renderEnvironmentSummary shows the category counts of every environment side by side, followed
+// by the resources that are not in the same category everywhere.
+func (m *model) renderEnvironmentSummary() {
+	m.viewPort.SetContent(workspaceSummary(m.workspace, m.viewPort.Width))
+	m.viewPort.GotoTop()
+}
+
+// workspaceSummary renders the aggregate summary of a workspace as plain text with styled headings.
+func workspaceSummary(workspace []*workspaceReport, width int) string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Category counts per environment") + "\n\n")
+	b.WriteString(fmt.Sprintf("%-18s", "CATEGORY"))
+	for _, ws := range workspace {
+		b.WriteString(fmt.Sprintf(" %12s", truncateRunes(ws.env, 12)))
+	}
+	b.WriteString("\n")
+	for _, cat := range resultCategories {
+		b.WriteString(fmt.Sprintf("%-18s", cat))
+		for _, ws := range workspace {
+			results, _ := visibleResults(ws.report, cat, false)
+			b.WriteString(fmt.Sprintf(" %12d", len(results)))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(fmt.Sprintf("%-18s", "EXECUTION LOGS"))
+	for _, ws := range workspace {
+		count := fmt.Sprintf("%d", len(ws.report.ExecutionLogs))
+		if ws.stream == nil || ws.loading {
+			count = "-"
+		}
+		b.WriteString(fmt.Sprintf(" %12s", count))
+	}
+	b.WriteString("\n")
+
+	divergent := divergentResources(workspace)
+	b.WriteString("\n" + titleStyle.Render(fmt.Sprintf("Resources that differ between environments (%d)", len(divergent))) + "\n\n")
+	if len(divergent) == 0 {
+		b.WriteString(helpStyle.Render("Every resource is in the same category in every environment.") + "\n")
+	}
+	for _, resource := range divergent {
+		b.WriteString(truncateRunes(resource.name, width) + "\n")
+		for _, ws := range workspace {
+			category, ok := resource.categories[ws.env]
+			if !ok {
+				category = "-"
+			}
+			style := valueStyle
+			if category == "DANGEROUS" || category == "ERROR" {
+				style = errorStyle
+			}
+			b.WriteString(fmt.Sprintf("    %-14s %s\n", ws.env, style.Render(category)))
+		}
+	}
+	return b.String()
+}
+
+// divergentResources lists the resources whose result category differs between environments
+// (including being absent from some of them), sorted by resource.
+func divergentResources(workspace []*workspaceReport) []resourceCategories {
+	byResource := make(map[string]map[string]string)
+	for _, ws := range workspace {
+		for _, cat := range resultCategories {
+			for _, item := range ws.report.Results.GetCategory(cat) {
+				if byResource[item.Resource] == nil {
+					byResource[item.Resource] = make(map[string]string)
+				}
+				byResource[item.Resource][ws.env] = cat
+			}
+		}
+	}
+	var divergent []resourceCategories
+	for _, name := range sortedKeys(byResource) {
+		categories := byResource[name]
+		same := len(categories) == len(workspace)
+		var first string
+		for _, cat := range categories {
+			if first == "" {
+				first = cat
+			} else if cat != first {
+				same = false
+			}
+		}
+		if !same {
+			divergent = append(divergent, resourceCategories{name: name, categories: categories})
+		}
+	}
+	return divergent
+}
+
+// truncateRunes shortens s to at most n runes.
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if n <= 0 || len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
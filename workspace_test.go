@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestResolveReportInputsSkipsOwnFiles(t *testing.T) {
+	dir := t.TempDir()
+	dev := writeReport(t, dir, "dev", JSONOutput{State: "dev"})
+	prod := writeReport(t, dir, "prod", JSONOutput{State: "prod"})
+	for _, name := range []string{historyFileName, ticketsLedgerFileName, "report.dev" + annotationsSuffix} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, input := range []string{dir, filepath.Join(dir, "*.json")} {
+		paths, err := resolveReportInputs(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{dev, prod}; !slices.Equal(paths, want) {
+			t.Errorf("resolveReportInputs(%q) = %v, want %v", input, paths, want)
+		}
+	}
+}
+
+func TestLoadWorkspaceRejectsDuplicateEnvironments(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"us-east-1", "us-west-2"} {
+		if err := os.Mkdir(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatal(err)
+		}
+		writeReport(t, filepath.Join(dir, sub), "prod", JSONOutput{State: sub})
+	}
+	writeReport(t, dir, "dev", JSONOutput{State: "dev"})
+
+	_, err := loadWorkspace(filepath.Join(dir, "*", "report.*.json"))
+	if err == nil || !strings.Contains(err.Error(), "both the prod environment") {
+		t.Fatalf("loadWorkspace error = %v, want the duplicate prod environment", err)
+	}
+	workspace, err := loadWorkspace(filepath.Join(dir, "report.*.json"))
+	if err != nil || len(workspace) != 1 || workspace[0].env != "dev" {
+		t.Fatalf("loadWorkspace = %v, %v, want the dev report", workspace, err)
+	}
+}